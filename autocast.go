@@ -0,0 +1,107 @@
+package rdsdataapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// typeMismatch matches the postgres error for a parameter that doesn't have the type of the
+// column it is assigned to, e.g: 'column "id" is of type integer but expression is of type
+// character varying'.
+var typeMismatch = regexp.MustCompile(`column "([^"]+)" is of type ([a-z][a-z0-9 ]*(?:\[\])?) but expression is of type`)
+
+// autoCast rewrites the query after it failed with err, if err reports a type mismatch for
+// a column that has a parameter of the same name (e.g. ':id' for column "id"). That parameter
+// is then wrapped as 'CAST(:id AS integer)'. It reports whether the query was rewritten.
+//
+// This is a heuristic: it only works when parameters are named after the columns they are
+// assigned to, which is common for INSERT and UPDATE statements.
+func autoCast(query string, err error) (string, bool) {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != rdsds.ErrCodeBadRequestException {
+		return query, false
+	}
+
+	m := typeMismatch.FindStringSubmatch(aerr.Message())
+	if m == nil {
+		return query, false
+	}
+
+	param := regexp.MustCompile(fmt.Sprintf(`(^|[^:\w]):%s\b`, regexp.QuoteMeta(m[1])))
+	if !param.MatchString(query) {
+		return query, false
+	}
+
+	return param.ReplaceAllString(query, fmt.Sprintf("${1}CAST(:%s AS %s)", m[1], m[2])), true
+}
+
+// limitMismatch matches the postgres error for a LIMIT or OFFSET parameter that is not an
+// integer, e.g: 'argument of LIMIT must be type bigint, not type character varying'.
+var limitMismatch = regexp.MustCompile(`argument of (LIMIT|OFFSET) must be type ([a-z][a-z0-9 ]*), not type`)
+
+// mysqlSyntaxError matches the MySQL error for a statement it can't parse, which is what it
+// reports for a LIMIT or OFFSET that is bound to a string: "... for the right syntax to use
+// near" followed by the quoted string.
+var mysqlSyntaxError = regexp.MustCompile(`right syntax to use near`)
+
+// limitParam matches a parameter bound to a LIMIT or OFFSET, e.g. 'LIMIT :n'
+var limitParam = regexp.MustCompile(`(?i)\b(LIMIT|OFFSET)(\s+):(\w+)\b`)
+
+// mysqlLimitParams matches the parameters bound to a LIMIT or OFFSET in MySQL, which also
+// has the 'LIMIT :offset, :count' form.
+var mysqlLimitParams = regexp.MustCompile(`(?i)\b(?:LIMIT|OFFSET)\s+:(\w+)\b(?:\s*,\s*:(\w+)\b)?`)
+
+// autoCastLimit rewrites the statement after it failed with err, if err reports that a
+// parameter of its LIMIT or OFFSET is not an integer. Postgres accepts an expression there,
+// so the parameter is wrapped as 'CAST(:n AS bigint)'. MySQL only accepts a placeholder, so
+// instead string parameters that hold an integer are sent as a long value. It reports
+// whether the statement was rewritten.
+func autoCastLimit(query string, params []*rdsds.SqlParameter, err error) (string, []*rdsds.SqlParameter, bool) {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != rdsds.ErrCodeBadRequestException {
+		return query, params, false
+	}
+
+	if m := limitMismatch.FindStringSubmatch(aerr.Message()); m != nil {
+		cast := limitParam.ReplaceAllStringFunc(query, func(s string) string {
+			sm := limitParam.FindStringSubmatch(s)
+			if !strings.EqualFold(sm[1], m[1]) {
+				return s
+			}
+
+			return fmt.Sprintf("%s%sCAST(:%s AS %s)", sm[1], sm[2], sm[3], m[2])
+		})
+
+		return cast, params, cast != query
+	}
+
+	if !mysqlSyntaxError.MatchString(aerr.Message()) {
+		return query, params, false
+	}
+
+	names := map[string]bool{}
+	for _, sm := range mysqlLimitParams.FindAllStringSubmatch(query, -1) {
+		names[sm[1]], names[sm[2]] = true, sm[2] != ""
+	}
+
+	ok, cast := false, make([]*rdsds.SqlParameter, len(params))
+	for i, p := range params {
+		cast[i] = p
+		if !names[aws.StringValue(p.Name)] || p.Value == nil || p.Value.StringValue == nil {
+			continue
+		}
+
+		if n, err := strconv.ParseInt(strings.TrimSpace(*p.Value.StringValue), 10, 64); err == nil {
+			cast[i], ok = &rdsds.SqlParameter{Name: p.Name, Value: &rdsds.Field{LongValue: aws.Int64(n)}}, true
+		}
+	}
+
+	return query, cast, ok
+}
@@ -0,0 +1,117 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestAutoCast(t *testing.T) {
+	mismatch := awserr.New(rdsds.ErrCodeBadRequestException, `ERROR: column "id" is of type integer but expression is of type character varying`, nil)
+	for i, c := range []struct {
+		query string
+		err   error
+		exp   string
+		ok    bool
+	}{
+		{"INSERT INTO foo (id, idx) VALUES (:id, :idx)", mismatch, "INSERT INTO foo (id, idx) VALUES (CAST(:id AS integer), :idx)", true},
+		{"UPDATE foo SET id=:id WHERE id=:id", mismatch, "UPDATE foo SET id=CAST(:id AS integer) WHERE id=CAST(:id AS integer)", true},
+		{"INSERT INTO foo (id) VALUES (:other)", mismatch, "INSERT INTO foo (id) VALUES (:other)", false},
+		{"INSERT INTO foo (id) VALUES (:id)", awserr.New(rdsds.ErrCodeBadRequestException, "syntax error", nil), "INSERT INTO foo (id) VALUES (:id)", false},
+		{"INSERT INTO foo (id) VALUES (:id)", awserr.New(rdsds.ErrCodeForbiddenException, mismatch.Message(), nil), "INSERT INTO foo (id) VALUES (:id)", false},
+	} {
+		act, ok := autoCast(c.query, c.err)
+		if act != c.exp || ok != c.ok {
+			t.Fatalf("%d: expected '%s' (%v), got: '%s' (%v)", i, c.exp, c.ok, act, ok)
+		}
+	}
+
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if aws.StringValue(in.Sql) == "INSERT INTO foo (id) VALUES (:id)" {
+			return nil, mismatch
+		}
+
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}}
+
+	conn := mockConn(t, m)
+	args := []driver.NamedValue{{Name: "id", Value: "1"}}
+	if _, err := conn.ExecContext(context.Background(), "INSERT INTO foo (id) VALUES (:id)", args); err == nil {
+		t.Fatalf("expected statement to fail without AutoCast")
+	}
+
+	conn.cfg.AutoCast = true
+	if _, err := conn.ExecContext(context.Background(), "INSERT INTO foo (id) VALUES (:id)", args); err != nil {
+		t.Fatalf("expected statement to succeed after casting, got: %v", err)
+	}
+
+	if len(m.executes) != 3 || aws.StringValue(m.executes[2].Sql) != "INSERT INTO foo (id) VALUES (CAST(:id AS integer))" {
+		t.Fatalf("expected a single retry with the cast, got: %d executions", len(m.executes))
+	}
+}
+
+func TestAutoCastLimit(t *testing.T) {
+	pgLimit := awserr.New(rdsds.ErrCodeBadRequestException, "ERROR: argument of LIMIT must be type bigint, not type character varying", nil)
+	pgOffset := awserr.New(rdsds.ErrCodeBadRequestException, "ERROR: argument of OFFSET must be type bigint, not type character varying", nil)
+	mysql := awserr.New(rdsds.ErrCodeBadRequestException, "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near ''10'' at line 1", nil)
+
+	str := func(name, v string) *rdsds.SqlParameter {
+		return &rdsds.SqlParameter{Name: aws.String(name), Value: &rdsds.Field{StringValue: aws.String(v)}}
+	}
+
+	long := func(name string, v int64) *rdsds.SqlParameter {
+		return &rdsds.SqlParameter{Name: aws.String(name), Value: &rdsds.Field{LongValue: aws.Int64(v)}}
+	}
+
+	for i, c := range []struct {
+		query  string
+		params []*rdsds.SqlParameter
+		err    error
+		exp    string
+		expPs  []*rdsds.SqlParameter
+		ok     bool
+	}{
+		{"SELECT * FROM foo LIMIT :n OFFSET :o", []*rdsds.SqlParameter{str("n", "10"), str("o", "5")}, pgLimit,
+			"SELECT * FROM foo LIMIT CAST(:n AS bigint) OFFSET :o", []*rdsds.SqlParameter{str("n", "10"), str("o", "5")}, true},
+		{"SELECT * FROM foo limit :n offset :o", nil, pgOffset, "SELECT * FROM foo limit :n offset CAST(:o AS bigint)", nil, true},
+		{"SELECT * FROM foo WHERE id = :n", nil, pgLimit, "SELECT * FROM foo WHERE id = :n", nil, false},
+		{"SELECT * FROM foo WHERE name = :name LIMIT :o, :n", []*rdsds.SqlParameter{str("name", "10"), str("o", "5"), str("n", " 10")}, mysql,
+			"SELECT * FROM foo WHERE name = :name LIMIT :o, :n", []*rdsds.SqlParameter{str("name", "10"), long("o", 5), long("n", 10)}, true},
+		{"SELECT * FROM foo LIMIT :n", []*rdsds.SqlParameter{str("n", "ten")}, mysql, "SELECT * FROM foo LIMIT :n", []*rdsds.SqlParameter{str("n", "ten")}, false},
+		{"SELECT * FROM foo LIMIT :n", []*rdsds.SqlParameter{long("n", 10)}, mysql, "SELECT * FROM foo LIMIT :n", []*rdsds.SqlParameter{long("n", 10)}, false},
+		{"SELECT * FROM foo LIMIT :n", nil, awserr.New(rdsds.ErrCodeForbiddenException, pgLimit.Message(), nil), "SELECT * FROM foo LIMIT :n", nil, false},
+	} {
+		act, ps, ok := autoCastLimit(c.query, c.params, c.err)
+		if act != c.exp || ok != c.ok || !reflect.DeepEqual(ps, c.expPs) {
+			t.Fatalf("%d: expected '%s' %v (%v), got: '%s' %v (%v)", i, c.exp, c.expPs, c.ok, act, ps, ok)
+		}
+	}
+
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if in.Parameters[0].Value.StringValue != nil {
+			return nil, mysql
+		}
+
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}}
+
+	conn := mockConn(t, m)
+	args := []driver.NamedValue{{Name: "n", Value: "10"}}
+	if _, err := conn.QueryContext(context.Background(), "SELECT * FROM foo LIMIT :n", args); err == nil {
+		t.Fatalf("expected statement to fail without AutoCast")
+	}
+
+	conn.cfg.AutoCast = true
+	if _, err := conn.QueryContext(context.Background(), "SELECT * FROM foo LIMIT :n", args); err != nil {
+		t.Fatalf("expected statement to succeed after casting, got: %v", err)
+	}
+
+	if len(m.executes) != 3 || aws.Int64Value(m.executes[2].Parameters[0].Value.LongValue) != 10 {
+		t.Fatalf("expected a single retry with a long value, got: %d executions", len(m.executes))
+	}
+}
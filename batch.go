@@ -0,0 +1,57 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchQuery is a query to execute with QueryBatch, and its arguments
+type BatchQuery struct {
+	Query string
+	Args  []interface{}
+}
+
+// BatchResult holds the rows of a query executed with QueryBatch, as returned by QueryMaps,
+// or the error the query failed with.
+type BatchResult struct {
+	Rows []map[string]interface{}
+	Err  error
+}
+
+// QueryBatch executes the queries concurrently, with at most concurrency of them in flight
+// (10 if it is less than one), and returns their results in the order of the queries. The
+// Data API executes a single statement per call, so this is faster than executing many small
+// lookups one after the other. Each query runs on its own connection of db, so a *sql.DB
+// should be passed: a *sql.Tx or *sql.Conn executes them one at a time. The connector's
+// MaxConcurrency still limits the calls of all handles together. Queries that are not
+// started before ctx is done fail with its error.
+func QueryBatch(ctx context.Context, db Queryer, concurrency int, queries ...BatchQuery) []BatchResult {
+	if concurrency < 1 {
+		concurrency = defaultMaxOpenConns
+	}
+
+	var (
+		res = make([]BatchResult, len(queries))
+		sem = make(chan struct{}, concurrency)
+		wg  sync.WaitGroup
+	)
+
+	for i, q := range queries {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			res[i].Err = fmt.Errorf("query %d was not executed: %w", i, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, q BatchQuery) {
+			defer func() { <-sem; wg.Done() }()
+			res[i].Rows, res[i].Err = QueryMaps(ctx, db, q.Query, q.Args...)
+		}(i, q)
+	}
+
+	wg.Wait()
+	return res
+}
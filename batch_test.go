@@ -0,0 +1,77 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestQueryBatch(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		inflight, peak int
+	)
+
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		mu.Lock()
+		if inflight++; inflight > peak {
+			peak = inflight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inflight--
+		mu.Unlock()
+
+		id := aws.Int64Value(in.Parameters[0].Value.LongValue)
+		if id == 3 {
+			return nil, errors.New("boom")
+		}
+
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("id")}},
+			Records:        [][]*rdsds.Field{{{LongValue: aws.Int64(id)}}},
+		}, nil
+	}}
+
+	var qs []BatchQuery
+	for i := 0; i < 6; i++ {
+		qs = append(qs, BatchQuery{Query: "SELECT id FROM foo WHERE id = :id", Args: []interface{}{sql.Named("id", i)}})
+	}
+
+	db := mockDB(t, m)
+	db.SetMaxOpenConns(4)
+
+	res := QueryBatch(context.Background(), db, 2, qs...)
+	for i, r := range res {
+		if i == 3 {
+			if r.Err == nil || !strings.Contains(r.Err.Error(), "boom") {
+				t.Fatalf("expected the error of query 3, got: %v", r.Err)
+			}
+
+			continue
+		}
+
+		if r.Err != nil || len(r.Rows) != 1 || r.Rows[0]["id"] != int64(i) {
+			t.Fatalf("expected the row of query %d in order, got: %v, %v", i, r.Rows, r.Err)
+		}
+	}
+
+	if peak != 2 {
+		t.Fatalf("expected 2 queries in flight, got: %d", peak)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res = QueryBatch(ctx, mockDB(t, m), 1, qs...); !errors.Is(res[len(res)-1].Err, context.Canceled) {
+		t.Fatalf("expected queries after cancelling to fail, got: %v", res[len(res)-1].Err)
+	}
+}
@@ -0,0 +1,135 @@
+package rdsdataapi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// StatementKind is the kind of an sql statement, as determined by ClassifyStatement
+type StatementKind int
+
+const (
+	StatementOther  StatementKind = iota // a statement of any other kind, e.g. SET or CALL
+	StatementSelect                      // a statement that only reads, e.g. SELECT or SHOW
+	StatementInsert                      // an INSERT or REPLACE statement
+	StatementUpdate                      // an UPDATE statement
+	StatementDelete                      // a DELETE statement
+	StatementDDL                         // a statement that changes the schema, e.g. CREATE or DROP
+)
+
+// String returns the name of the statement kind
+func (k StatementKind) String() string {
+	switch k {
+	case StatementSelect:
+		return "SELECT"
+	case StatementInsert:
+		return "INSERT"
+	case StatementUpdate:
+		return "UPDATE"
+	case StatementDelete:
+		return "DELETE"
+	case StatementDDL:
+		return "DDL"
+	default:
+		return "OTHER"
+	}
+}
+
+// statementKeywords maps the leading keyword of a statement onto its kind
+var statementKeywords = map[string]StatementKind{
+	"SELECT": StatementSelect, "SHOW": StatementSelect, "DESCRIBE": StatementSelect,
+	"DESC": StatementSelect, "VALUES": StatementSelect, "TABLE": StatementSelect,
+	"INSERT": StatementInsert, "REPLACE": StatementInsert,
+	"UPDATE": StatementUpdate,
+	"DELETE": StatementDelete,
+	"CREATE": StatementDDL, "ALTER": StatementDDL, "DROP": StatementDDL,
+	"TRUNCATE": StatementDDL, "RENAME": StatementDDL, "COMMENT": StatementDDL,
+}
+
+// ClassifyStatement returns the kind of the sql statement, based on its leading keyword.
+// Comments, whitespace and parentheses are skipped, for a statement with common table
+// expressions (WITH ... AS (...)) the statement following them determines the kind. A
+// SELECT ... INTO writes, into a new table on postgres or into variables or a file on
+// MySQL, so it is StatementOther. Comments and quotes of both engines are recognized.
+func ClassifyStatement(sql string) StatementKind { return classifyStatement("", sql) }
+
+// classifyStatement implements ClassifyStatement for the syntax of the engine, it is used to
+// decide on the inclusion of result metadata, routing to the reader and whether a statement
+// is safe to retry.
+func classifyStatement(engine, sql string) StatementKind {
+	cte, words := false, topLevelWords(engine, sql)
+	for i, w := range words {
+		w = strings.ToUpper(w)
+		if kind, ok := statementKeywords[w]; ok {
+			if w == "SELECT" && hasInto(words[i+1:]) {
+				return StatementOther
+			}
+
+			return kind
+		}
+
+		if w == "WITH" && !cte {
+			cte = true
+			continue
+		}
+
+		if !cte {
+			return StatementOther
+		}
+	}
+
+	return StatementOther
+}
+
+// hasInto returns whether the words contain the INTO keyword
+func hasInto(words []string) bool {
+	for _, w := range words {
+		if strings.EqualFold(w, "INTO") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// topLevelWords returns the words of sql that are outside of comments, quotes and
+// parentheses, in order. Parentheses that precede the first word, as in '(SELECT 1) UNION
+// (SELECT 2)', are considered to be the top level. Comments and quotes are recognized as
+// with SplitStatements for the engine, an unterminated one ends the words.
+func topLevelWords(engine, sql string) (words []string) {
+	sp := splitter{rs: []rune(sql), engine: engine}
+	depth, top := 0, 0
+	for i := 0; i < len(sp.rs); i++ {
+		end, _, err := sp.token(i)
+		if err != nil {
+			return
+		}
+
+		if end > i {
+			i = end
+			continue
+		}
+
+		switch r := sp.rs[i]; {
+		case r == '(':
+			if depth++; len(words) == 0 && depth == top+1 {
+				top++
+			}
+		case r == ')':
+			depth--
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(sp.rs) && (unicode.IsLetter(sp.rs[j]) || unicode.IsDigit(sp.rs[j]) || sp.rs[j] == '_') {
+				j++
+			}
+
+			if depth == top {
+				words = append(words, string(sp.rs[i:j]))
+			}
+
+			i = j - 1
+		}
+	}
+
+	return
+}
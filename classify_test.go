@@ -0,0 +1,80 @@
+package rdsdataapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	for i, c := range []struct {
+		sql string
+		exp StatementKind
+	}{
+		{"SELECT 1", StatementSelect},
+		{"  \n\tselect * FROM foo", StatementSelect},
+		{"-- comment\nSELECT 1", StatementSelect},
+		{"/* multi\nline */ /* twice */ SELECT 1", StatementSelect},
+		{"# mysql comment\nDELETE FROM foo", StatementDelete},
+		{"(SELECT 1) UNION (SELECT 2)", StatementSelect},
+		{"SHOW TABLES", StatementSelect},
+		{"INSERT INTO foo VALUES (1)", StatementInsert},
+		{"REPLACE INTO foo VALUES (1)", StatementInsert},
+		{"UPDATE foo SET a = 1", StatementUpdate},
+		{"DELETE FROM foo", StatementDelete},
+		{"CREATE TABLE foo (id INT)", StatementDDL},
+		{"drop table foo", StatementDDL},
+		{"WITH x AS (SELECT id FROM foo) SELECT * FROM x", StatementSelect},
+		{"WITH x AS (SELECT id FROM foo) DELETE FROM foo WHERE id IN (SELECT id FROM x)", StatementDelete},
+		{"WITH RECURSIVE x(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM x), y AS (SELECT ')') UPDATE foo SET n = 1", StatementUpdate},
+		{"WITH \"select\" AS (DELETE FROM foo RETURNING *) INSERT INTO bar SELECT * FROM \"select\"", StatementInsert},
+		{"SELECT * INTO bar FROM foo", StatementOther},
+		{"select id into @a from foo", StatementOther},
+		{"WITH x AS (SELECT 1) SELECT * INTO bar FROM x", StatementOther},
+		{"SELECT * FROM foo WHERE id IN (SELECT id FROM bar) AND a = 'into'", StatementSelect},
+		{"INSERT INTO foo SELECT * FROM bar", StatementInsert},
+		{"SET @a = 1", StatementOther},
+		{"CALL proc()", StatementOther},
+		{"", StatementOther},
+		{"-- only a comment", StatementOther},
+	} {
+		if act := ClassifyStatement(c.sql); act != c.exp {
+			t.Fatalf("%d: expected '%s' to be %v, got: %v", i, c.sql, c.exp, act)
+		}
+	}
+}
+
+func TestClassifyStatementEngines(t *testing.T) {
+	for i, c := range []struct {
+		engine, sql string
+		exp         StatementKind
+	}{
+		{EnginePostgres, "/* a /* b */ DELETE FROM foo */ SELECT 1", StatementSelect},
+		{EngineMySQL, "/* a /* b */ DELETE FROM foo */ SELECT 1", StatementDelete},
+		{EngineMySQL, "# c\nDELETE FROM foo", StatementDelete},
+		{EnginePostgres, "SELECT E'\\' INTO' FROM foo", StatementSelect},
+		{EnginePostgres, "SELECT 'a\\' INTO bar", StatementOther},
+		{EngineMySQL, "SELECT 'a\\' INTO bar'", StatementSelect},
+	} {
+		if act := classifyStatement(c.engine, c.sql); act != c.exp {
+			t.Fatalf("%d: expected '%s' to be %v on %s, got: %v", i, c.sql, c.exp, c.engine, act)
+		}
+	}
+}
+
+func TestIncludeMetadata(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+	for _, q := range []string{"SELECT 1", "DELETE FROM foo", "INSERT INTO foo VALUES (1) RETURNING id"} {
+		if _, err := c.QueryContext(context.Background(), q, nil); err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+	}
+
+	for i, exp := range []bool{true, false, true} {
+		if act := aws.BoolValue(m.executes[i].IncludeResultMetadata); act != exp {
+			t.Fatalf("%d: expected metadata inclusion to be %v, got: %v", i, exp, act)
+		}
+	}
+}
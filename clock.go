@@ -0,0 +1,69 @@
+package rdsdataapi
+
+import (
+	"context"
+	"time"
+)
+
+// clock tells the time and waits for durations. All timing of the driver goes through the
+// clock of the connector, such that tests can replace it to control the time.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the clock of the time package, it is the default of every connector
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// withTimeout returns a context derived from ctx that is done once clk reports that the
+// timeout passed, as context.WithTimeout does for the real clock.
+func withTimeout(ctx context.Context, clk clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clk.(realClock); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	inner, cancel := context.WithCancel(ctx)
+	tctx := &timeoutCtx{Context: inner, expired: make(chan struct{})}
+	go func() {
+		select {
+		case <-clk.After(timeout):
+			close(tctx.expired)
+			cancel()
+		case <-inner.Done():
+		}
+	}()
+
+	return tctx, cancel
+}
+
+// timeoutCtx is a context that reports context.DeadlineExceeded once its timeout expired
+type timeoutCtx struct {
+	context.Context
+	expired chan struct{}
+}
+
+func (c *timeoutCtx) Err() error {
+	err := c.Context.Err()
+	if err == nil {
+		return nil
+	}
+
+	select {
+	case <-c.expired:
+		return context.DeadlineExceeded
+	default:
+		return err
+	}
+}
+
+// clock returns the clock of the connection's connector, or the real clock without one
+func (c *Conn) clock() clock {
+	if c.connector == nil || c.connector.clock == nil {
+		return realClock{}
+	}
+
+	return c.connector.clock
+}
@@ -0,0 +1,154 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// fakeClock is a clock whose time only moves when it is advanced. With auto set, After
+// advances it by the duration right away such that waiting takes no time at all.
+type fakeClock struct {
+	auto bool
+
+	mu     sync.Mutex
+	now    time.Time
+	waits  []time.Duration // the durations passed to After
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock(auto bool) *fakeClock {
+	return &fakeClock{auto: auto, now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	f.waits = append(f.waits, d)
+	f.timers = append(f.timers, fakeTimer{at: f.now.Add(d), c: make(chan time.Time, 1)})
+	c := f.timers[len(f.timers)-1].c
+	f.mu.Unlock()
+
+	if f.auto || d <= 0 {
+		f.Advance(d)
+	}
+
+	return c
+}
+
+// Advance moves the time forward by d and fires the timers that are due
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d > 0 {
+		f.now = f.now.Add(d)
+	}
+
+	var pending []fakeTimer
+	for _, t := range f.timers {
+		if t.at.After(f.now) {
+			pending = append(pending, t)
+			continue
+		}
+
+		t.c <- f.now
+	}
+
+	f.timers = pending
+}
+
+// Waits returns the durations that were waited for
+func (f *fakeClock) Waits() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration{}, f.waits...)
+}
+
+func TestWithTimeout(t *testing.T) {
+	clk := newFakeClock(false)
+	ctx, cancel := withTimeout(context.Background(), clk, time.Second)
+	defer cancel()
+
+	for len(clk.Waits()) == 0 {
+		time.Sleep(time.Millisecond) // until the timer is set
+	}
+
+	clk.Advance(999 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatalf("expected the context to be valid before the timeout, got: %v", ctx.Err())
+	}
+
+	clk.Advance(time.Millisecond)
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got: %v", ctx.Err())
+	}
+
+	ctx, cancel = withTimeout(context.Background(), clk, time.Second)
+	if cancel(); !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("expected the context to be cancelled, got: %v", ctx.Err())
+	}
+}
+
+func TestConnectorClock(t *testing.T) {
+	m := failingAPI(2, "ThrottlingException")
+	cfg := testCfg
+	cfg.MaxRetries, cfg.RetryJitter = 2, JitterNone
+	c := newConnector(cfg, m)
+	clk := newFakeClock(true)
+	c.setClock(clk)
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	start := time.Now()
+	res, err := conn.(*Conn).ExecContext(context.Background(), "DELETE FROM foo", nil)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Fatalf("expected the backoff to take no real time")
+	}
+
+	if d := res.(*Result).duration; d != 300*time.Millisecond {
+		t.Fatalf("expected the duration to include the backoff of the fake clock, got: %v", d)
+	}
+
+	clk = newFakeClock(false)
+	c.setClock(clk)
+	m.CommitTransaction = func(in *rdsds.CommitTransactionInput) (*rdsds.CommitTransactionOutput, error) {
+		for len(clk.Waits()) == 0 {
+			time.Sleep(time.Millisecond) // until the timeout is set
+		}
+
+		clk.Advance(defaultOperationTimeout)
+		<-m.lastCtx.Done()
+		return nil, m.lastCtx.Err()
+	}
+
+	if _, err = conn.(*Conn).BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	if err = conn.(*Conn).Commit(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the commit to time out on the fake clock, got: %v", err)
+	}
+}
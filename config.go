@@ -0,0 +1,648 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// Config holds the configuration of the driver. It can be parsed from a connection
+// string (DSN) with ParseDSN or constructed directly to be used with NewConnector.
+type Config struct {
+	Database    string // name of the database on which queries will be performed
+	ResourceARN string // the aws resource accessed with this conn
+	SecretARN   string // the aws secret that provides access to the resource
+	Region      string // the aws region of the resource, see resolveRegion for fallbacks
+	Profile     string // the shared config profile used to load aws config and credentials
+	Schema      string // the default schema for statements, can be overwritten with WithSchema
+	Endpoint    string // overwrites the endpoint of the Data API, e.g. for a VPC endpoint or a local emulator
+	CABundle    string // path of a PEM file with the CA certificates that are trusted for calls to AWS
+	ProxyURL    string // url of the proxy through which calls to AWS are made
+
+	// EndpointResolver resolves the endpoints and signing regions of the AWS services used by
+	// the driver, e.g. for a partition that the SDK doesn't know of. It can't be set in a DSN.
+	// When nil the SDK's resolver is used, it selects the partition from the region.
+	EndpointResolver endpoints.Resolver
+
+	// CredentialsProvider provides the credentials for calls to AWS, e.g. from Vault or a
+	// custom SSO flow. It can't be set in a DSN. Credentials are retrieved again once the
+	// provider reports them as expired. When nil the credentials of the Profile, or else of
+	// the SDK's default chain, are used.
+	CredentialsProvider credentials.Provider
+
+	// TLSSkipVerify disables the verification of the certificates presented by AWS. This is
+	// strongly discouraged, configure a CABundle for intercepting proxies instead.
+	TLSSkipVerify bool
+
+	// ReaderResourceARN is the aws resource that queries executed with a context from
+	// WithReader are send to, e.g. to route reads to an Aurora reader. When empty the
+	// ResourceARN is used for all statements.
+	ReaderResourceARN string
+
+	// SDKMaxRetries configures the nr of retries performed by the AWS SDK itself for each
+	// API call. When nil the SDK's default for the RDS Data API is used, set it to zero to
+	// disable SDK level retries.
+	SDKMaxRetries *int
+
+	// MaxRetries configures the nr of times the driver retries a statement that failed with
+	// a transport or throttling error. This happens on top of any retries by the SDK. Statements
+	// in a transaction are not retried, unless their context was created with WithRetryInTx.
+	MaxRetries int
+
+	// RetryBaseDelay is the delay before the first driver level retry, it doubles for every
+	// next retry up to RetryMaxDelay. Defaults to 100ms and 5s respectively.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// RetryJitter determines how the retry delay is randomized: JitterFull (the default)
+	// waits a random duration up to the delay, JitterNone waits the exact delay.
+	RetryJitter string
+
+	// CapacityRetries configures the nr of times the driver retries a statement that failed
+	// because Aurora Serverless is scaling or at its max capacity (see ErrCapacity), with the
+	// same backoff as MaxRetries. Defaults to 0, returning the error right away.
+	CapacityRetries int
+
+	// CapacityErrorSubstrings overwrites the substrings of which an error message must contain
+	// one to be classified as a capacity error, see DefaultCapacityErrorSubstrings.
+	CapacityErrorSubstrings []string
+
+	// DecimalReturnType configures how DECIMAL columns are returned by the Data API, either
+	// as "STRING" or as "DOUBLE_OR_LONG". When empty the Data API's default (STRING) is used.
+	// It can be overwritten per statement with WithResultSetOptions.
+	DecimalReturnType string
+
+	// NumericAsString makes all numeric columns (integers, decimals and floats) be returned
+	// as a string. It implies a DecimalReturnType of STRING, so DECIMAL and NUMERIC values
+	// are exact, as are integers. The Data API returns REAL and DOUBLE values as doubles
+	// though, so those are the shortest representation of the float64 the driver received.
+	NumericAsString bool
+
+	// DecimalValues makes DECIMAL and NUMERIC columns be returned as a Decimal, which can be
+	// scanned into a float64 as well as into a string. It requires a DecimalReturnType of
+	// STRING, the default.
+	DecimalValues bool
+
+	// ReturningColumn enables LastInsertId for engines that don't report generated fields
+	// (postgres). When set, 'RETURNING <ReturningColumn>' is appended to executed INSERT
+	// statements of a single row of VALUES that have no RETURNING clause of their own. Off by
+	// default, as it rewrites the statement.
+	ReturningColumn string
+
+	// AutoCast makes a statement that failed because a parameter doesn't have the type of
+	// the column it is assigned to be retried once, with the parameter wrapped in a CAST to
+	// the column's type. This is a heuristic for postgres, see autoCast for its limitations.
+	// Statements that failed because a LIMIT or OFFSET parameter is not an integer are
+	// retried once as well, see autoCastLimit.
+	AutoCast bool
+
+	// OrdinalParams names arguments that are passed without sql.Named after their position,
+	// such that a statement can refer to them as :1, :2, etc. Arguments with a name keep it.
+	OrdinalParams bool
+
+	// EmptyStringAsNull sends string arguments that are empty as NULL, e.g. for migrating data
+	// from a database that doesn't distinguish the two. It doesn't apply to Text, Decimal or
+	// the arguments of a ParamSet, which are converted without a connection.
+	EmptyStringAsNull bool
+
+	// ImplicitTx makes every statement that is executed outside of a transaction run in
+	// a short-lived transaction of its own. This costs two extra API calls per statement.
+	ImplicitTx bool
+
+	// BatchTx makes the batch of a prepared statement that is closed outside of a transaction
+	// run in a transaction of its own, such that all its parameter sets are applied or none.
+	BatchTx bool
+
+	// ColumnNames determines what is reported as the names of the columns of a result:
+	// ColumnNamesLabel (the default) for the column's label (e.g. an alias), falling back to
+	// the name if a column has no label, or ColumnNamesName to always report its name.
+	ColumnNames string
+
+	// DedupColumns makes columns that have the same name as an earlier column of a result
+	// (e.g. when joining tables) be reported with a '_2', '_3', etc. suffix.
+	DedupColumns bool
+
+	// TinyIntAsInt makes TINYINT(1) columns be returned as integers. By default they are
+	// returned as bool, since that is how MySQL stores a BOOLEAN.
+	TinyIntAsInt bool
+
+	// ParseTime makes DATE, DATETIME and TIMESTAMP columns be returned as time.Time (in UTC)
+	// instead of the string that the Data API returns.
+	ParseTime bool
+
+	// UUIDColumns are the names (or labels) of columns that hold UUIDs as BINARY(16), their
+	// values are returned in the canonical string form of an UUID instead of as 16 bytes.
+	UUIDColumns []string
+
+	// ZeroDateHandling determines how MySQL's zero date ('0000-00-00') is returned when
+	// ParseTime is enabled: ZeroDateError (the default) fails, ZeroDateNull returns NULL and
+	// ZeroDateZeroTime returns the zero time.Time.
+	ZeroDateHandling string
+
+	// BatchSize is the max nr of parameter sets that a prepared statement sends per call to
+	// the Data API when it is closed, more sets are split over multiple calls. Batches are
+	// split earlier if their size would exceed the Data API's request limit. Defaults to 500.
+	BatchSize int
+
+	// MaxParamBytes is the max size of a single string or blob parameter. Larger parameters
+	// are rejected before the statement is send, as the Data API would reject the request
+	// with a less clear error. Defaults to 4MiB, the max size of a request to the Data API.
+	MaxParamBytes int
+
+	// MaxConcurrency limits the nr of statements and batches that the connections of a
+	// connector have in flight with the Data API at the same time, to smooth out bursts that
+	// would be throttled. Zero means no limit.
+	MaxConcurrency int
+
+	// MaxOpenConns is the max nr of open connections of a database handle opened with OpenDB,
+	// each connection has at most one call in flight with the Data API. Zero means the default
+	// of 10, a negative value means no limit (as with sql.DB.SetMaxOpenConns).
+	MaxOpenConns int
+
+	// MaxIdleConns is the max nr of idle connections that a database handle opened with OpenDB
+	// keeps. Idle connections hold no resources so zero means the same as MaxOpenConns, or the
+	// default of 10 if MaxOpenConns is negative. A negative value means none are kept.
+	MaxIdleConns int
+
+	// Engine is the database engine of the cluster, EngineMySQL or EnginePostgres. When empty
+	// it is detected when first needed.
+	Engine string
+
+	// GuardUnboundedWrites guards against UPDATE and DELETE statements without a WHERE clause:
+	// GuardLog logs them and GuardReject fails them with ErrUnboundedWrite. When empty they
+	// are executed as any other statement. It can be overwritten per statement with
+	// WithUnboundedWrite.
+	GuardUnboundedWrites string
+
+	// ReadOnlyConnection makes the driver reject every statement that ClassifyStatement
+	// doesn't classify as a SELECT, and every transaction that is not read-only, with
+	// ErrReadOnly. This is enforced by the driver only, the Data API has no read-only mode.
+	ReadOnlyConnection bool
+
+	// StatementCacheSize enables caching what the driver derives from the sql of a statement
+	// (its kind and the guards that apply) for this nr of distinct statements, the least
+	// recently used are evicted. The cache is shared by the connections of a connector.
+	// Disabled when zero.
+	StatementCacheSize int
+
+	// ValidationQuery is executed to check that a connection works, by Ping and Check. It
+	// must be a SELECT statement so health checks can't write. Defaults to 'SELECT 1'.
+	ValidationQuery string
+
+	// StatementTimeout is applied with the engine's SET statement at the start of each
+	// transaction, bounding every statement in it on the database side. The Data API doesn't
+	// keep session state outside of transactions, combine it with ImplicitTx to bound all
+	// statements.
+	StatementTimeout time.Duration
+
+	// OperationTimeout bounds operations for which the sql package doesn't provide a
+	// context, such as committing or rolling back a transaction, and operations whose
+	// context has no deadline. Defaults to one minute.
+	OperationTimeout time.Duration
+
+	// QueryTimeout, ExecTimeout, TxBeginTimeout, TxCommitTimeout and TxRollbackTimeout
+	// overwrite the OperationTimeout for queries, other statements (and batches), and
+	// beginning, committing and rolling back transactions respectively. Like it, they only
+	// apply to operations without a deadline of their own.
+	QueryTimeout      time.Duration
+	ExecTimeout       time.Duration
+	TxBeginTimeout    time.Duration
+	TxCommitTimeout   time.Duration
+	TxRollbackTimeout time.Duration
+
+	// StatementInterceptor is called before each statement is send to the Data API and
+	// may change its sql or parameters. It can't be configured through the DSN.
+	StatementInterceptor StatementInterceptor
+
+	// SlowQueryThreshold enables reporting statements (and batches) whose call to the Data API,
+	// including retries, took longer than it. They are reported to SlowQueryFunc or else logged
+	// with the standard logger. Disabled when zero.
+	SlowQueryThreshold time.Duration
+
+	// SlowQueryFunc is called for each statement that exceeded the SlowQueryThreshold, it can't
+	// be configured through the DSN.
+	SlowQueryFunc SlowQueryFunc
+
+	// RedactParams replaces the values of parameters with a placeholder of their type (and
+	// length) when they are part of an error, the log or the SlowQueryFunc, as they may hold
+	// personal data. Names are kept. When nil it defaults to true, a statement's context can
+	// show the values regardless with WithParamValues.
+	RedactParams *bool
+
+	// Tags attribute the driver's activity to e.g. a team or service. The Data API doesn't
+	// support tagging requests, so they are added to the User-Agent of each call (which is
+	// recorded by CloudTrail) and to the messages the driver logs.
+	Tags map[string]string
+
+	// Metrics receives the activity of the driver, it is optional and can't be configured
+	// through the DSN.
+	Metrics MetricsCollector
+}
+
+// SlowQueryFunc is called with the sql of a slow statement, how long it took and a
+// description of each of its parameters as ':name=value', with the values redacted as
+// configured by RedactParams. For batches the parameters of all sets are described.
+type SlowQueryFunc func(ctx context.Context, sql string, d time.Duration, params []string)
+
+// StatementInterceptor is called with the sql and parameters of a statement before it is
+// executed. It returns the sql and parameters that will be send to the Data API instead,
+// returning an error aborts the statement. For batches of prepared statements it is called
+// for each parameter set and it must return the same sql for each of them.
+type StatementInterceptor func(ctx context.Context, sql string, params []*rdsds.SqlParameter) (string, []*rdsds.SqlParameter, error)
+
+const (
+	// ColumnNamesName reports the name of each column as the column name
+	ColumnNamesName = "name"
+
+	// ColumnNamesLabel reports the label of each column as the column name
+	ColumnNamesLabel = "label"
+)
+
+// ParseDSN parses a connection string, formatted as an url query, into a Config. It does
+// not check if all required values are present, that happens when the Config is used.
+func ParseDSN(dsn string) (cfg Config, err error) {
+	vals, err := url.ParseQuery(dsn)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to parse conn string as url query: %w", err)
+	}
+
+	cfg.Database = vals.Get("Database")
+	cfg.ResourceARN = vals.Get("ResourceARN")
+	cfg.SecretARN = vals.Get("SecretARN")
+	cfg.Region = vals.Get("Region")
+	cfg.Profile = vals.Get("Profile")
+	cfg.Endpoint = vals.Get("Endpoint")
+	cfg.CABundle = vals.Get("CABundle")
+	cfg.ProxyURL = vals.Get("ProxyURL")
+
+	if v := vals.Get("TLSSkipVerify"); v != "" {
+		if cfg.TLSSkipVerify, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'TLSSkipVerify' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	cfg.Schema = vals.Get("Schema")
+	cfg.ReaderResourceARN = vals.Get("ReaderResourceARN")
+	cfg.DecimalReturnType = vals.Get("DecimalReturnType")
+	cfg.ReturningColumn = vals.Get("ReturningColumn")
+
+	if v := vals.Get("DecimalValues"); v != "" {
+		if cfg.DecimalValues, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'DecimalValues' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("NumericAsString"); v != "" {
+		if cfg.NumericAsString, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'NumericAsString' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("RedactParams"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("configuration value 'RedactParams' must be a boolean, got: '%s'", v)
+		}
+
+		cfg.RedactParams = &b
+	}
+
+	if v := vals.Get("SDKMaxRetries"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("configuration value 'SDKMaxRetries' must be a non-negative integer, got: '%s'", v)
+		}
+
+		cfg.SDKMaxRetries = &n
+	}
+
+	if v := vals.Get("MaxRetries"); v != "" {
+		if cfg.MaxRetries, err = strconv.Atoi(v); err != nil || cfg.MaxRetries < 0 {
+			return cfg, fmt.Errorf("configuration value 'MaxRetries' must be a non-negative integer, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("CapacityRetries"); v != "" {
+		if cfg.CapacityRetries, err = strconv.Atoi(v); err != nil || cfg.CapacityRetries < 0 {
+			return cfg, fmt.Errorf("configuration value 'CapacityRetries' must be a non-negative integer, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("CapacityErrorSubstrings"); v != "" {
+		cfg.CapacityErrorSubstrings = strings.Split(v, ",")
+	}
+
+	if v := vals.Get("RetryBaseDelay"); v != "" {
+		if cfg.RetryBaseDelay, err = time.ParseDuration(v); err != nil || cfg.RetryBaseDelay < 0 {
+			return cfg, fmt.Errorf("configuration value 'RetryBaseDelay' must be a non-negative duration, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("RetryMaxDelay"); v != "" {
+		if cfg.RetryMaxDelay, err = time.ParseDuration(v); err != nil || cfg.RetryMaxDelay < 0 {
+			return cfg, fmt.Errorf("configuration value 'RetryMaxDelay' must be a non-negative duration, got: '%s'", v)
+		}
+	}
+
+	cfg.RetryJitter = vals.Get("RetryJitter")
+
+	if v := vals.Get("AutoCast"); v != "" {
+		if cfg.AutoCast, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'AutoCast' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("OrdinalParams"); v != "" {
+		if cfg.OrdinalParams, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'OrdinalParams' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("EmptyStringAsNull"); v != "" {
+		if cfg.EmptyStringAsNull, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'EmptyStringAsNull' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("BatchTx"); v != "" {
+		if cfg.BatchTx, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'BatchTx' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("ImplicitTx"); v != "" {
+		if cfg.ImplicitTx, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'ImplicitTx' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	cfg.ColumnNames = vals.Get("ColumnNames")
+
+	if v := vals.Get("DedupColumns"); v != "" {
+		if cfg.DedupColumns, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'DedupColumns' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("TinyIntAsInt"); v != "" {
+		if cfg.TinyIntAsInt, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'TinyIntAsInt' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("ParseTime"); v != "" {
+		if cfg.ParseTime, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'ParseTime' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("Tags"); v != "" {
+		if cfg.Tags, err = parseTags(v); err != nil {
+			return cfg, err
+		}
+	}
+
+	if v := vals.Get("UUIDColumns"); v != "" {
+		cfg.UUIDColumns = strings.Split(v, ",")
+	}
+
+	cfg.ZeroDateHandling = vals.Get("ZeroDateHandling")
+
+	if v := vals.Get("BatchSize"); v != "" {
+		if cfg.BatchSize, err = strconv.Atoi(v); err != nil || cfg.BatchSize < 1 {
+			return cfg, fmt.Errorf("configuration value 'BatchSize' must be a positive integer, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("MaxParamBytes"); v != "" {
+		if cfg.MaxParamBytes, err = strconv.Atoi(v); err != nil || cfg.MaxParamBytes < 0 {
+			return cfg, fmt.Errorf("configuration value 'MaxParamBytes' must be a non-negative integer, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("MaxConcurrency"); v != "" {
+		if cfg.MaxConcurrency, err = strconv.Atoi(v); err != nil || cfg.MaxConcurrency < 0 {
+			return cfg, fmt.Errorf("configuration value 'MaxConcurrency' must be a non-negative integer, got: '%s'", v)
+		}
+	}
+
+	for k, v := range map[string]*int{"MaxOpenConns": &cfg.MaxOpenConns, "MaxIdleConns": &cfg.MaxIdleConns} {
+		if s := vals.Get(k); s != "" {
+			if *v, err = strconv.Atoi(s); err != nil {
+				return cfg, fmt.Errorf("configuration value '%s' must be an integer, got: '%s'", k, s)
+			}
+		}
+	}
+
+	cfg.Engine = vals.Get("Engine")
+	cfg.GuardUnboundedWrites = vals.Get("GuardUnboundedWrites")
+
+	if v := vals.Get("StatementCacheSize"); v != "" {
+		if cfg.StatementCacheSize, err = strconv.Atoi(v); err != nil || cfg.StatementCacheSize < 0 {
+			return cfg, fmt.Errorf("configuration value 'StatementCacheSize' must be a non-negative integer, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("ReadOnlyConnection"); v != "" {
+		if cfg.ReadOnlyConnection, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("configuration value 'ReadOnlyConnection' must be a boolean, got: '%s'", v)
+		}
+	}
+
+	if cfg.ValidationQuery = vals.Get("ValidationQuery"); cfg.ValidationQuery != "" {
+		if err = checkValidationQuery(cfg.Engine, cfg.ValidationQuery); err != nil {
+			return cfg, err
+		}
+	}
+
+	if v := vals.Get("StatementTimeout"); v != "" {
+		if cfg.StatementTimeout, err = time.ParseDuration(v); err != nil || cfg.StatementTimeout < 0 {
+			return cfg, fmt.Errorf("configuration value 'StatementTimeout' must be a non-negative duration, got: '%s'", v)
+		}
+	}
+
+	if v := vals.Get("SlowQueryThreshold"); v != "" {
+		if cfg.SlowQueryThreshold, err = time.ParseDuration(v); err != nil || cfg.SlowQueryThreshold < 0 {
+			return cfg, fmt.Errorf("configuration value 'SlowQueryThreshold' must be a non-negative duration, got: '%s'", v)
+		}
+	}
+
+	for _, o := range []struct {
+		name string
+		d    *time.Duration
+	}{
+		{"OperationTimeout", &cfg.OperationTimeout},
+		{"QueryTimeout", &cfg.QueryTimeout},
+		{"ExecTimeout", &cfg.ExecTimeout},
+		{"TxBeginTimeout", &cfg.TxBeginTimeout},
+		{"TxCommitTimeout", &cfg.TxCommitTimeout},
+		{"TxRollbackTimeout", &cfg.TxRollbackTimeout},
+	} {
+		if v := vals.Get(o.name); v != "" {
+			if *o.d, err = time.ParseDuration(v); err != nil || *o.d < 0 {
+				return cfg, fmt.Errorf("configuration value '%s' must be a non-negative duration, got: '%s'", o.name, v)
+			}
+		}
+	}
+
+	return
+}
+
+// defaultOperationTimeout is used when no OperationTimeout is configured
+const defaultOperationTimeout = time.Minute
+
+// orOperationTimeout returns the timeout of a specific operation if it is configured, or
+// else the configured operation timeout or the default.
+func (cfg Config) orOperationTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+
+	if cfg.OperationTimeout > 0 {
+		return cfg.OperationTimeout
+	}
+
+	return defaultOperationTimeout
+}
+
+// maxRequestBytes is the max size of a request to the Data API
+const maxRequestBytes = 4 << 20
+
+// defaultMaxParamBytes is used when no MaxParamBytes is configured
+const defaultMaxParamBytes = maxRequestBytes
+
+// defaultValidationQuery is used when no ValidationQuery is configured
+const defaultValidationQuery = "SELECT 1"
+
+// redactParams returns whether parameter values are redacted from logs, the default is true
+func (cfg Config) redactParams() bool {
+	return cfg.RedactParams == nil || *cfg.RedactParams
+}
+
+// validationQuery returns the configured ValidationQuery or the default
+func (cfg Config) validationQuery() string {
+	if cfg.ValidationQuery != "" {
+		return cfg.ValidationQuery
+	}
+
+	return defaultValidationQuery
+}
+
+// poolSize returns the max nr of open and idle connections for a database handle opened
+// with OpenDB. Negative values are passed on as is, the sql package treats them as no limit
+// and no idle connections respectively. Without a limit on open connections the default
+// idle connections are kept, rather than an unlimited nr.
+func (cfg Config) poolSize() (open, idle int) {
+	open, idle = cfg.MaxOpenConns, cfg.MaxIdleConns
+	if open == 0 {
+		open = defaultMaxOpenConns
+	}
+
+	if idle == 0 {
+		idle = open
+		if idle < 0 {
+			idle = defaultMaxOpenConns
+		}
+	}
+
+	return
+}
+
+// checkValidationQuery returns an error if the query is not a read-only statement
+func checkValidationQuery(engine, query string) error {
+	if kind := classifyStatement(engine, query); kind != StatementSelect {
+		return fmt.Errorf("configuration value 'ValidationQuery' must be a SELECT statement, got a statement of kind '%s': '%s'", kind, query)
+	}
+
+	return nil
+}
+
+// defaultBatchSize is used when no BatchSize is configured, the Data API accepts at most
+// 1000 parameter sets per batch.
+const defaultBatchSize = 500
+
+// batchSize returns the configured batch size or the default
+func (cfg Config) batchSize() int {
+	if cfg.BatchSize > 0 {
+		return cfg.BatchSize
+	}
+
+	return defaultBatchSize
+}
+
+// maxParamBytes returns the configured max parameter size or the default
+func (cfg Config) maxParamBytes() int {
+	if cfg.MaxParamBytes > 0 {
+		return cfg.MaxParamBytes
+	}
+
+	return defaultMaxParamBytes
+}
+
+// validate checks if the configuration can be used to connect
+func (cfg Config) validate() error {
+	if cfg.ResourceARN == "" || cfg.SecretARN == "" || cfg.Database == "" {
+		return fmt.Errorf("required configuration value 'Database', 'ResourceARN' or 'SecretARN' are missing")
+	}
+
+	switch cfg.RetryJitter {
+	case "", JitterFull, JitterNone:
+	default:
+		return fmt.Errorf("configuration value 'RetryJitter' must be '%s' or '%s', got: '%s'", JitterFull, JitterNone, cfg.RetryJitter)
+	}
+
+	switch cfg.ColumnNames {
+	case "", ColumnNamesName, ColumnNamesLabel:
+	default:
+		return fmt.Errorf("configuration value 'ColumnNames' must be '%s' or '%s', got: '%s'", ColumnNamesName, ColumnNamesLabel, cfg.ColumnNames)
+	}
+
+	if cfg.NumericAsString && cfg.DecimalReturnType == rdsds.DecimalReturnTypeDoubleOrLong {
+		return fmt.Errorf("configuration value 'NumericAsString' can't be combined with a 'DecimalReturnType' of '%s'", cfg.DecimalReturnType)
+	}
+
+	if cfg.DecimalValues && cfg.DecimalReturnType == rdsds.DecimalReturnTypeDoubleOrLong {
+		return fmt.Errorf("configuration value 'DecimalValues' can't be combined with a 'DecimalReturnType' of '%s'", cfg.DecimalReturnType)
+	}
+
+	switch cfg.Engine {
+	case "", EngineMySQL, EnginePostgres:
+	default:
+		return fmt.Errorf("configuration value 'Engine' must be '%s' or '%s', got: '%s'", EngineMySQL, EnginePostgres, cfg.Engine)
+	}
+
+	switch cfg.GuardUnboundedWrites {
+	case "", GuardLog, GuardReject:
+	default:
+		return fmt.Errorf("configuration value 'GuardUnboundedWrites' must be '%s' or '%s', got: '%s'", GuardLog, GuardReject, cfg.GuardUnboundedWrites)
+	}
+
+	if cfg.ValidationQuery != "" {
+		if err := checkValidationQuery(cfg.Engine, cfg.ValidationQuery); err != nil {
+			return err
+		}
+	}
+
+	switch cfg.ZeroDateHandling {
+	case "", ZeroDateError, ZeroDateNull, ZeroDateZeroTime:
+	default:
+		return fmt.Errorf("configuration value 'ZeroDateHandling' must be '%s', '%s' or '%s', got: '%s'",
+			ZeroDateError, ZeroDateNull, ZeroDateZeroTime, cfg.ZeroDateHandling)
+	}
+
+	switch cfg.DecimalReturnType {
+	case "", rdsds.DecimalReturnTypeString, rdsds.DecimalReturnTypeDoubleOrLong:
+	default:
+		return fmt.Errorf("configuration value 'DecimalReturnType' must be '%s' or '%s', got: '%s'",
+			rdsds.DecimalReturnTypeString, rdsds.DecimalReturnTypeDoubleOrLong, cfg.DecimalReturnType)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package rdsdataapi_test
+
+import (
+	"testing"
+	"time"
+
+	rdsdataapi "github.com/advanderveer/rds-data-api"
+)
+
+func TestParseDSN(t *testing.T) {
+	cfg, err := rdsdataapi.ParseDSN("Database=foo&ResourceARN=arn:res&SecretARN=arn:sec")
+	if err != nil {
+		t.Fatalf("failed to parse dsn: %v", err)
+	}
+
+	if cfg.Database != "foo" || cfg.ResourceARN != "arn:res" || cfg.SecretARN != "arn:sec" {
+		t.Fatalf("unexpected config after parsing, got: %+v", cfg)
+	}
+
+	if cfg.SDKMaxRetries != nil {
+		t.Fatalf("SDK max retries should default to nil, got: %v", *cfg.SDKMaxRetries)
+	}
+}
+
+func TestParseDSNSDKMaxRetries(t *testing.T) {
+	cfg, err := rdsdataapi.ParseDSN("SDKMaxRetries=0")
+	if err != nil {
+		t.Fatalf("failed to parse dsn: %v", err)
+	}
+
+	if cfg.SDKMaxRetries == nil || *cfg.SDKMaxRetries != 0 {
+		t.Fatalf("expected SDK retries to be disabled, got: %v", cfg.SDKMaxRetries)
+	}
+
+	_, err = rdsdataapi.ParseDSN("SDKMaxRetries=-1")
+	if err == nil {
+		t.Fatalf("expected error for negative SDK max retries")
+	}
+}
+
+func TestParseDSNTimeouts(t *testing.T) {
+	cfg, err := rdsdataapi.ParseDSN("QueryTimeout=5s&TxCommitTimeout=2m")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if cfg.QueryTimeout != 5*time.Second || cfg.TxCommitTimeout != 2*time.Minute || cfg.ExecTimeout != 0 {
+		t.Fatalf("unexpected timeouts, got: %v, %v and %v", cfg.QueryTimeout, cfg.TxCommitTimeout, cfg.ExecTimeout)
+	}
+
+	if _, err = rdsdataapi.ParseDSN("TxRollbackTimeout=-1s"); err == nil {
+		t.Fatalf("expected an error for a negative timeout")
+	}
+}
@@ -0,0 +1,284 @@
+package rdsdataapi
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// newSession creates the AWS session for a connector, it is a variable so it can be
+// replaced while testing.
+var newSession = session.NewSessionWithOptions
+
+// Connector holds a parsed configuration and the AWS client that is shared by all
+// connections it creates. It can be used to construct a database handle with sql.OpenDB.
+type Connector struct {
+	cfg            Config
+	rdsDataService dataAPI
+	secrets        secretsAPI
+	retryer        *retryer
+
+	secretMu  sync.Mutex
+	secretARN string // the ARN cfg.SecretARN resolved to if it was configured by name
+
+	detectMu sync.Mutex // held while detecting the engine, see Conn.engine
+	engineMu sync.Mutex
+	engine   string // the engine of the cluster once detected, see Conn.engine
+
+	counters *counters     // shared with the retryer and the connectors created by WithDatabase
+	inflight chan struct{} // limits the concurrent calls to MaxConcurrency, nil without a limit
+	clock    clock         // shared with the retryer, see setClock
+	stmts    *stmtCache    // analyses of statements by their sql, nil without a StatementCacheSize
+}
+
+// NewConnector validates the config and sets up the AWS client used by its connections.
+func NewConnector(cfg Config) (_ *Connector, err error) {
+	if err = cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	opts, err := sessionOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.CustomCABundle != nil {
+		defer opts.CustomCABundle.(io.Closer).Close()
+	}
+
+	sess, err := newSession(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup aws session: %w", err)
+	}
+
+	region, err := resolveRegion(cfg, sess)
+	if err != nil {
+		return nil, err
+	}
+
+	awscfg := aws.NewConfig().WithRegion(region)
+	if cfg.SDKMaxRetries != nil {
+		awscfg = awscfg.WithMaxRetries(*cfg.SDKMaxRetries)
+	}
+
+	if cfg.EndpointResolver != nil {
+		awscfg = awscfg.WithEndpointResolver(cfg.EndpointResolver)
+	}
+
+	if cfg.CredentialsProvider != nil {
+		awscfg = awscfg.WithCredentials(credentials.NewCredentials(cfg.CredentialsProvider))
+	}
+
+	rdscfg := awscfg.Copy()
+	if cfg.Endpoint != "" {
+		rdscfg = rdscfg.WithEndpoint(cfg.Endpoint)
+	}
+
+	api := rdsds.New(sess, rdscfg)
+	if len(cfg.Tags) > 0 {
+		api.Handlers.Build.PushBack(request.MakeAddToUserAgentFreeFormHandler(userAgent(cfg.Tags)))
+	}
+
+	cfg.Region = region // the effective region, as reported by Conn.Config
+	c := newConnector(cfg, api)
+	c.secrets = secretsmanager.New(sess, awscfg)
+	return c, nil
+}
+
+// defaultMaxOpenConns is the number of connections a database handle opened with OpenDB
+// keeps open at most, unless configured with MaxOpenConns.
+const defaultMaxOpenConns = 10
+
+// OpenDB validates the config and returns a database handle for it, as with sql.OpenDB and
+// NewConnector. Each open connection may have a call in flight with the Data API, so their
+// number is limited (see MaxOpenConns) to avoid being throttled. Connections are stateless
+// so they are kept idle instead of being closed (see MaxIdleConns).
+func OpenDB(cfg Config) (*sql.DB, error) {
+	c, err := NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(c)
+	open, idle := cfg.poolSize()
+	db.SetMaxOpenConns(open)
+	db.SetMaxIdleConns(idle)
+	return db, nil
+}
+
+// sessionOptions returns the options for the AWS session of a connector. If a CA bundle is
+// configured its file is opened, the caller must close it after the session is created.
+func sessionOptions(cfg Config) (opts session.Options, err error) {
+	opts = session.Options{
+		Profile:           cfg.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	}
+
+	if cfg.ProxyURL != "" || cfg.TLSSkipVerify {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.ProxyURL != "" {
+			u, err := url.Parse(cfg.ProxyURL)
+			if err != nil {
+				return opts, fmt.Errorf("failed to parse proxy url: %w", err)
+			}
+
+			tr.Proxy = http.ProxyURL(u)
+		}
+
+		if cfg.TLSSkipVerify {
+			tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+
+		opts.Config.HTTPClient = &http.Client{Transport: tr}
+	}
+
+	if cfg.CABundle != "" {
+		f, err := os.Open(cfg.CABundle)
+		if err != nil {
+			return opts, fmt.Errorf("failed to open CA bundle: %w", err)
+		}
+
+		opts.CustomCABundle = f
+	}
+
+	return
+}
+
+// newConnector creates a connector that uses the provided api for all its connections
+// without any further validation, it allows tests to inject a mock of the Data API.
+func newConnector(cfg Config, api dataAPI) *Connector {
+	if cfg.NumericAsString {
+		cfg.DecimalReturnType = rdsds.DecimalReturnTypeString
+	}
+
+	cs := &counters{}
+	r := newRetryer(cfg)
+	r.counters = cs
+	c := &Connector{cfg: cfg, rdsDataService: api, retryer: r, counters: cs, clock: r.clock}
+	if cfg.MaxConcurrency > 0 {
+		c.inflight = make(chan struct{}, cfg.MaxConcurrency)
+	}
+
+	if cfg.StatementCacheSize > 0 {
+		c.stmts = newStmtCache(cfg.StatementCacheSize)
+	}
+
+	return c
+}
+
+// resolveRegion determines the region of the Data API. It uses the first region that is
+// found in the following order: the 'Region' configuration value, the region of the
+// resource ARN, the AWS_REGION or AWS_DEFAULT_REGION environment variables and finally the
+// shared config file (for the configured profile) as loaded by the session. The region
+// selects the partition, e.g. a resource in 'us-gov-west-1' is reached through GovCloud.
+func resolveRegion(cfg Config, sess *session.Session) (string, error) {
+	var arnRegion string
+	if a, err := arn.Parse(cfg.ResourceARN); err == nil {
+		arnRegion = a.Region
+	}
+
+	for _, r := range []string{
+		cfg.Region,
+		arnRegion,
+		os.Getenv("AWS_REGION"),
+		os.Getenv("AWS_DEFAULT_REGION"),
+		aws.StringValue(sess.Config.Region),
+	} {
+		if r != "" {
+			return r, nil
+		}
+	}
+
+	return "", fmt.Errorf("no aws region configured, provide it with the 'Region' configuration value, the AWS_REGION environment variable or a shared config file")
+}
+
+// Connect returns a connection to the database. The Data API is stateless so this
+// doesn't perform any network calls, unless the secret was configured by name and it
+// needs to be resolved to an ARN.
+func (c *Connector) Connect(ctx context.Context) (_ driver.Conn, err error) {
+	secretARN, err := c.resolveSecretARN(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.counters.inc(statConnections)
+	return &Conn{
+		databaseName:   c.cfg.Database,
+		resourceARN:    c.cfg.ResourceARN,
+		secretARN:      secretARN,
+		rdsDataService: c.rdsDataService,
+		cfg:            c.cfg,
+		retryer:        c.retryer,
+		connector:      c,
+	}, nil
+}
+
+// WithDatabase returns a copy of the connector for another database on the same cluster.
+// The copy shares the AWS clients, retryer, Stats and MaxConcurrency limit with c, so
+// opening a database handle per database doesn't set up a new AWS session for each. Both
+// connectors remain usable.
+func (c *Connector) WithDatabase(name string) *Connector {
+	c.secretMu.Lock()
+	secretARN := c.secretARN
+	c.secretMu.Unlock()
+
+	c.engineMu.Lock()
+	engine := c.engine
+	c.engineMu.Unlock()
+
+	cfg := c.cfg
+	cfg.Database = name
+	return &Connector{
+		cfg:            cfg,
+		rdsDataService: c.rdsDataService,
+		secrets:        c.secrets,
+		retryer:        c.retryer,
+		secretARN:      secretARN,
+		engine:         engine,
+		counters:       c.counters,
+		inflight:       c.inflight,
+		clock:          c.clock,
+		stmts:          c.stmts,
+	}
+}
+
+// setClock replaces the clock of the connector and its retryer, for testing
+func (c *Connector) setClock(clk clock) {
+	c.clock, c.retryer.clock = clk, clk
+}
+
+// limit calls fn once the connection may have another call in flight with the Data API, as
+// limited by MaxConcurrency. It returns the context's error if it is done before then.
+func (c *Conn) limit(ctx context.Context, fn func() error) error {
+	if c.connector == nil || c.connector.inflight == nil {
+		return fn()
+	}
+
+	select {
+	case c.connector.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a call to the Data API to finish ('MaxConcurrency'): %w", ctx.Err())
+	}
+
+	defer func() { <-c.connector.inflight }()
+	return fn()
+}
+
+// Driver returns the underlying driver of the connector, it is the same driver that is
+// registered with the sql package.
+func (c *Connector) Driver() driver.Driver { return drv }
@@ -0,0 +1,355 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// mockSession replaces the session constructor with one that returns a session with the
+// provided region as if it was read from the shared config file.
+func mockSession(sharedRegion string) func() {
+	orig := newSession
+	newSession = func(opts session.Options) (*session.Session, error) {
+		return session.NewSession(aws.NewConfig().WithRegion(sharedRegion))
+	}
+
+	return func() { newSession = orig }
+}
+
+// setenv sets an environment variable and returns a function that restores the original
+func setenv(tb testing.TB, k, v string) func() {
+	orig, ok := os.LookupEnv(k)
+	if err := os.Setenv(k, v); err != nil {
+		tb.Fatalf("failed to set env: %v", err)
+	}
+
+	return func() {
+		if ok {
+			os.Setenv(k, orig)
+		} else {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestConnectorRegionResolution(t *testing.T) {
+	for i, c := range []struct {
+		cfgRegion    string
+		envRegion    string
+		sharedRegion string
+		expRegion    string
+	}{
+		{"eu-west-1", "eu-central-1", "us-east-1", "eu-west-1"},
+		{"", "eu-central-1", "us-east-1", "eu-central-1"},
+		{"", "", "us-east-1", "us-east-1"},
+		{"", "", "", ""},
+	} {
+		c := c
+		t.Run(fmt.Sprintf("case-%d", i), func(t *testing.T) {
+			defer mockSession(c.sharedRegion)()
+			defer setenv(t, "AWS_REGION", c.envRegion)()
+			defer setenv(t, "AWS_DEFAULT_REGION", "")()
+
+			conn, err := NewConnector(Config{Database: "db", ResourceARN: "arn:res", SecretARN: "arn:sec", Region: c.cfgRegion})
+			if c.expRegion == "" {
+				if err == nil {
+					t.Fatalf("expected error without any region")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to create connector: %v", err)
+			}
+
+			if act := aws.StringValue(conn.rdsDataService.(*rdsds.RDSDataService).Client.Config.Region); act != c.expRegion {
+				t.Fatalf("expected region '%s', got: '%s'", c.expRegion, act)
+			}
+		})
+	}
+}
+
+// fakeResolver resolves every endpoint to a fixed url and records what was resolved
+type fakeResolver struct{ services, regions []string }
+
+func (r *fakeResolver) EndpointFor(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+	r.services, r.regions = append(r.services, service), append(r.regions, region)
+	return endpoints.ResolvedEndpoint{URL: "https://" + service + ".fake.local", SigningRegion: "fake-" + region}, nil
+}
+
+func TestConnectorPartitions(t *testing.T) {
+	defer mockSession("")()
+	defer setenv(t, "AWS_REGION", "eu-west-1")()
+
+	for i, c := range []struct {
+		arn         string
+		expEndpoint string
+	}{
+		{"arn:aws-us-gov:rds:us-gov-west-1:123456789012:cluster:foo", "https://rds-data.us-gov-west-1.amazonaws.com"},
+		{"arn:aws-cn:rds:cn-north-1:123456789012:cluster:foo", "https://rds-data.cn-north-1.amazonaws.com.cn"},
+		{"arn:res", "https://rds-data.eu-west-1.amazonaws.com"},
+	} {
+		conn, err := NewConnector(Config{Database: "db", ResourceARN: c.arn, SecretARN: "arn:sec"})
+		if err != nil {
+			t.Fatalf("%d: failed to create connector: %v", i, err)
+		}
+
+		if act := conn.rdsDataService.(*rdsds.RDSDataService).Client.Endpoint; act != c.expEndpoint {
+			t.Fatalf("%d: expected endpoint '%s', got: '%s'", i, c.expEndpoint, act)
+		}
+	}
+
+	r := &fakeResolver{}
+	conn, err := NewConnector(Config{Database: "db", ResourceARN: "arn:aws-cn:rds:cn-north-1:123456789012:cluster:foo", SecretARN: "arn:sec", EndpointResolver: r})
+	if err != nil {
+		t.Fatalf("failed to create connector: %v", err)
+	}
+
+	if cl := conn.rdsDataService.(*rdsds.RDSDataService).Client; cl.Endpoint != "https://rds-data.fake.local" || cl.SigningRegion != "fake-cn-north-1" {
+		t.Fatalf("expected endpoint of the fake resolver, got: '%s' signed for '%s'", cl.Endpoint, cl.SigningRegion)
+	}
+
+	if len(r.regions) != 2 || r.regions[0] != "cn-north-1" {
+		t.Fatalf("expected resolver to be used for both services in the region of the arn, got: %v", r.regions)
+	}
+}
+
+func TestConnectorDriver(t *testing.T) {
+	c, err := NewConnector(testCfg)
+	if err != nil {
+		t.Fatalf("failed to create connector: %v", err)
+	}
+
+	db := sql.OpenDB(c)
+	if d, ok := db.Driver().(*Driver); !ok || d != drv {
+		t.Fatalf("expected the registered driver, got: %T", db.Driver())
+	}
+
+	other, err := sql.Open("rds-data-api", "Database=db&ResourceARN=arn:res&SecretARN=arn:sec&Region=eu-west-1")
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+
+	if other.Driver() != db.Driver() {
+		t.Fatalf("expected the same driver for both databases")
+	}
+}
+
+func TestOpenDB(t *testing.T) {
+	defer mockSession("eu-west-1")()
+	if _, err := OpenDB(Config{ResourceARN: "arn:res"}); err == nil {
+		t.Fatalf("expected an invalid config to fail")
+	}
+
+	db, err := OpenDB(testCfg)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+
+	defer db.Close()
+	if d, ok := db.Driver().(*Driver); !ok || d != drv {
+		t.Fatalf("expected the registered driver, got: %T", db.Driver())
+	}
+
+	if st := db.Stats(); st.MaxOpenConnections != defaultMaxOpenConns {
+		t.Fatalf("expected the default max open connections, got: %d", st.MaxOpenConnections)
+	}
+
+	cfg := testCfg
+	cfg.MaxOpenConns = 3
+	db, err = OpenDB(cfg)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+
+	defer db.Close()
+	if st := db.Stats(); st.MaxOpenConnections != 3 {
+		t.Fatalf("expected the configured max open connections, got: %d", st.MaxOpenConnections)
+	}
+}
+
+func TestConfigPoolSize(t *testing.T) {
+	for _, c := range []struct {
+		open, idle       int
+		expOpen, expIdle int
+	}{
+		{0, 0, defaultMaxOpenConns, defaultMaxOpenConns},
+		{4, 0, 4, 4},
+		{4, 2, 4, 2},
+		{4, -1, 4, -1},
+		{-1, 0, -1, defaultMaxOpenConns},
+		{-1, 3, -1, 3},
+		{-1, -1, -1, -1},
+	} {
+		open, idle := Config{MaxOpenConns: c.open, MaxIdleConns: c.idle}.poolSize()
+		if open != c.expOpen || idle != c.expIdle {
+			t.Fatalf("expected %d/%d for %d/%d, got: %d/%d", c.expOpen, c.expIdle, c.open, c.idle, open, idle)
+		}
+	}
+
+	cfg, err := ParseDSN("MaxOpenConns=-1&MaxIdleConns=5")
+	if err != nil || cfg.MaxOpenConns != -1 || cfg.MaxIdleConns != 5 {
+		t.Fatalf("expected pool options to be parsed, got: %+v %v", cfg, err)
+	}
+
+	if _, err = ParseDSN("MaxOpenConns=many"); err == nil {
+		t.Fatalf("expected an error for a non-integer")
+	}
+}
+
+// stubProvider provides static credentials that expire when it is told so
+type stubProvider struct {
+	retrieved int
+	expired   bool
+}
+
+func (p *stubProvider) Retrieve() (credentials.Value, error) {
+	p.retrieved, p.expired = p.retrieved+1, false
+	return credentials.Value{AccessKeyID: fmt.Sprintf("STUBKEY%d", p.retrieved), SecretAccessKey: "secret", ProviderName: "stub"}, nil
+}
+
+func (p *stubProvider) IsExpired() bool { return p.expired }
+
+func TestConnectorCredentialsProvider(t *testing.T) {
+	defer mockSession("eu-west-1")()
+	defer setenv(t, "AWS_ACCESS_KEY_ID", "ENVKEY")()
+	defer setenv(t, "AWS_SECRET_ACCESS_KEY", "secret")()
+
+	var auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "{}")
+	}))
+	defer srv.Close()
+
+	p := &stubProvider{}
+	cfg := testCfg
+	cfg.ResourceARN, cfg.SecretARN = "arn:aws:rds:eu-west-1:123456789012:cluster:db", "arn:aws:secretsmanager:eu-west-1:123456789012:secret:db"
+	cfg.Endpoint, cfg.CredentialsProvider = srv.URL, p
+	db, err := OpenDB(cfg)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+
+	defer db.Close()
+	for i := 0; i < 3; i++ {
+		p.expired = i == 2
+		if _, err = db.Exec("DELETE FROM foo WHERE id = 1"); err != nil {
+			t.Fatalf("failed to exec: %v", err)
+		}
+	}
+
+	if len(auths) != 3 || !strings.Contains(auths[0], "Credential=STUBKEY1/") || !strings.Contains(auths[1], "Credential=STUBKEY1/") {
+		t.Fatalf("expected calls to be signed with the provider's credentials, got: %v", auths)
+	}
+
+	if p.retrieved != 2 || !strings.Contains(auths[2], "Credential=STUBKEY2/") {
+		t.Fatalf("expected expired credentials to be retrieved again, got: %d retrievals and %v", p.retrieved, auths)
+	}
+}
+
+func TestConnectorTransportOptions(t *testing.T) {
+	opts, err := sessionOptions(testCfg)
+	if err != nil || opts.Config.HTTPClient != nil || opts.CustomCABundle != nil {
+		t.Fatalf("expected the default transport, got: %+v (%v)", opts, err)
+	}
+
+	cfg := testCfg
+	cfg.ProxyURL = "http://proxy.local:3128"
+	cfg.TLSSkipVerify = true
+	if opts, err = sessionOptions(cfg); err != nil {
+		t.Fatalf("failed to get session options: %v", err)
+	}
+
+	tr := opts.Config.HTTPClient.Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "https://rds-data.eu-west-1.amazonaws.com", nil)
+	if u, _ := tr.Proxy(req); u == nil || u.Host != "proxy.local:3128" || !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Fatalf("expected proxy and skipped verification, got: %v and %+v", u, tr.TLSClientConfig)
+	}
+
+	cfg = testCfg
+	cfg.CABundle = filepath.Join("testdata", "missing.pem")
+	if _, err = sessionOptions(cfg); err == nil {
+		t.Fatalf("expected error for missing CA bundle")
+	}
+
+	cfg.Endpoint = "http://localhost:8080"
+	cfg.CABundle = ""
+	conn, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create connector: %v", err)
+	}
+
+	if act := conn.rdsDataService.(*rdsds.RDSDataService).Client.Endpoint; act != cfg.Endpoint {
+		t.Fatalf("expected custom endpoint, got: %v", act)
+	}
+}
+
+func TestConnectorWithDatabase(t *testing.T) {
+	m := &mockAPI{}
+	c := newConnector(testCfg, m)
+	other := c.WithDatabase("other")
+
+	for _, db := range []*sql.DB{sql.OpenDB(c), sql.OpenDB(other)} {
+		if _, err := db.Exec("DELETE FROM foo"); err != nil {
+			t.Fatalf("failed to exec: %v", err)
+		}
+	}
+
+	if aws.StringValue(m.executes[0].Database) != "db" || aws.StringValue(m.executes[1].Database) != "other" {
+		t.Fatalf("expected each connector to use its own database, got: %v and %v", m.executes[0].Database, m.executes[1].Database)
+	}
+
+	if other.rdsDataService != c.rdsDataService || other.retryer != c.retryer {
+		t.Fatalf("expected the copy to share the api client and retryer")
+	}
+}
+
+func TestConnectorMaxConcurrency(t *testing.T) {
+	cfg := testCfg
+	cfg.MaxConcurrency = 2
+	conn, err := newConnector(cfg, &mockAPI{}).Connect(context.Background())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	c := conn.(*Conn)
+	release, started := make(chan struct{}), make(chan struct{})
+	for i := 0; i < cfg.MaxConcurrency; i++ {
+		go c.limit(context.Background(), func() error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+
+		<-started
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = c.ExecContext(ctx, "DELETE FROM foo", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the statement to wait until its context is done, got: %v", err)
+	}
+
+	close(release)
+	if _, err = c.ExecContext(context.Background(), "DELETE FROM foo", nil); err != nil {
+		t.Fatalf("expected the statement to be executed once calls finished, got: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package rdsdataapi
+
+import (
+	"context"
+
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// ctxKey is the type of keys for values that are stored in a context by this package
+type ctxKey int
+
+const (
+	resultSetOptionsKey ctxKey = iota
+	retryInTxKey
+	schemaKey
+	readerKey
+	paramsKey
+	resultMetadataKey
+	rawParamsKey
+	unboundedWriteKey
+	resourceKey
+	paramValuesKey
+	driverStatementKey
+)
+
+// WithResultSetOptions returns a context that makes statements executed with it use the
+// provided result set options instead of the connection's defaults.
+func WithResultSetOptions(ctx context.Context, opts *rdsds.ResultSetOptions) context.Context {
+	return context.WithValue(ctx, resultSetOptionsKey, opts)
+}
+
+// resultSetOptionsFromContext returns the result set options stored in the context, if any
+func resultSetOptionsFromContext(ctx context.Context) (opts *rdsds.ResultSetOptions, ok bool) {
+	opts, ok = ctx.Value(resultSetOptionsKey).(*rdsds.ResultSetOptions)
+	return
+}
+
+// WithRetryInTx returns a context that allows statements executed with it to be retried
+// even if they are part of a transaction. Only use this for statements that are safe to
+// be applied more than once.
+func WithRetryInTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryInTxKey, true)
+}
+
+// retryInTxFromContext returns whether retries within a transaction are allowed
+func retryInTxFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(retryInTxKey).(bool)
+	return ok
+}
+
+// WithSchema returns a context that makes statements executed with it use the provided
+// schema instead of the connection's default. For prepared statements the schema from the
+// context used to prepare the statement is used for the whole batch.
+func WithSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaKey, schema)
+}
+
+// schemaFromContext returns the schema stored in the context, if any
+func schemaFromContext(ctx context.Context) (schema string, ok bool) {
+	schema, ok = ctx.Value(schemaKey).(string)
+	return
+}
+
+// WithReader returns a context that makes queries executed with it outside of a transaction
+// use the configured ReaderResourceARN, e.g. to route them to an Aurora reader. Writes (see
+// ClassifyStatement) and statements that are part of a transaction always use the ResourceARN.
+func WithReader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readerKey, true)
+}
+
+// readerFromContext returns whether the query should be routed to the reader
+func readerFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(readerKey).(bool)
+	return ok
+}
+
+// WithParams returns a context that makes statements executed with it, without arguments of
+// their own, use the parameter set. This skips converting the arguments for every statement.
+func WithParams(ctx context.Context, ps ParamSet) context.Context {
+	return context.WithValue(ctx, paramsKey, ps)
+}
+
+// paramsFromContext returns the parameters of the set stored in the context, if any
+func paramsFromContext(ctx context.Context) ([]*rdsds.SqlParameter, bool) {
+	ps, ok := ctx.Value(paramsKey).(ParamSet)
+	return ps.params, ok
+}
+
+// WithResultMetadata returns a context that makes statements executed with it request the
+// result metadata, or not, regardless of what the driver decides from the statement itself.
+// Without metadata the rows of a result have no columns and can't be read.
+func WithResultMetadata(ctx context.Context, include bool) context.Context {
+	return context.WithValue(ctx, resultMetadataKey, include)
+}
+
+// resultMetadataFromContext returns whether result metadata is requested according to the
+// context, if it holds that choice
+func resultMetadataFromContext(ctx context.Context) (include bool, ok bool) {
+	include, ok = ctx.Value(resultMetadataKey).(bool)
+	return
+}
+
+// WithRawParams returns a context that makes statements executed with it send the provided
+// parameters to the Data API as-is, e.g. for type hints or values that the driver can't
+// convert arguments into. Such statements must not have arguments of their own. The raw
+// parameters are not used for the batches of prepared statements.
+func WithRawParams(ctx context.Context, params []*rdsds.SqlParameter) context.Context {
+	return context.WithValue(ctx, rawParamsKey, params)
+}
+
+// rawParamsFromContext returns the raw parameters stored in the context, if any
+func rawParamsFromContext(ctx context.Context) (params []*rdsds.SqlParameter, ok bool) {
+	params, ok = ctx.Value(rawParamsKey).([]*rdsds.SqlParameter)
+	return
+}
+
+// WithUnboundedWrite returns a context that allows UPDATE and DELETE statements executed with
+// it to have no WHERE clause, regardless of the GuardUnboundedWrites mode.
+func WithUnboundedWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unboundedWriteKey, true)
+}
+
+// unboundedWriteFromContext returns whether unbounded writes are allowed
+func unboundedWriteFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(unboundedWriteKey).(bool)
+	return ok
+}
+
+// resource identifies the cluster and secret that a call to the Data API is made with
+type resource struct{ resourceARN, secretARN string }
+
+// WithResource returns a context that makes statements executed with it use the provided
+// resource and secret ARNs instead of the connection's, e.g. to send an occasional statement
+// to another cluster. The database and schema remain those of the connection. A transaction
+// stays on the resource it began with: statements in it fail if their context doesn't
+// provide the same ARNs. For prepared statements the ARNs from the context used to prepare
+// the statement are used for the whole batch.
+func WithResource(ctx context.Context, resourceARN, secretARN string) context.Context {
+	return context.WithValue(ctx, resourceKey, resource{resourceARN: resourceARN, secretARN: secretARN})
+}
+
+// resourceFromContext returns the resource stored in the context, if any
+func resourceFromContext(ctx context.Context) (r resource, ok bool) {
+	r, ok = ctx.Value(resourceKey).(resource)
+	return
+}
+
+// WithParamValues returns a context that makes errors and slow statement reports of statements
+// executed with it show the values of their parameters, even if RedactParams is enabled. Use
+// it while debugging, the values may hold personal data.
+func WithParamValues(ctx context.Context) context.Context {
+	return context.WithValue(ctx, paramValuesKey, true)
+}
+
+// paramValuesFromContext returns whether parameter values may be shown
+func paramValuesFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(paramValuesKey).(bool)
+	return ok
+}
+
+// withDriverStatement returns a context that marks the statement executed with it as one the
+// driver executes itself, e.g. to configure the session, such that it isn't guarded and isn't
+// wrapped in an implicit transaction.
+func withDriverStatement(ctx context.Context) context.Context {
+	return context.WithValue(ctx, driverStatementKey, true)
+}
+
+// driverStatementFromContext returns whether the statement is executed by the driver itself
+func driverStatementFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(driverStatementKey).(bool)
+	return ok
+}
@@ -0,0 +1,227 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestResultSetOptionsFromContext(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+	c.cfg.DecimalReturnType = rdsds.DecimalReturnTypeDoubleOrLong
+
+	ctx := context.Background()
+	if _, err := c.QueryContext(ctx, "SELECT 1.5", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	ctx = WithResultSetOptions(ctx, &rdsds.ResultSetOptions{DecimalReturnType: aws.String(rdsds.DecimalReturnTypeString)})
+	if _, err := c.QueryContext(ctx, "SELECT 1.5", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if act := aws.StringValue(m.executes[0].ResultSetOptions.DecimalReturnType); act != rdsds.DecimalReturnTypeDoubleOrLong {
+		t.Fatalf("expected connection default to be used, got: %v", act)
+	}
+
+	if act := aws.StringValue(m.executes[1].ResultSetOptions.DecimalReturnType); act != rdsds.DecimalReturnTypeString {
+		t.Fatalf("expected context options to be used, got: %v", act)
+	}
+}
+
+func TestSchemaFromContext(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+	c.cfg.Schema = "public"
+
+	ctx := context.Background()
+	if _, err := c.ExecContext(ctx, "DELETE FROM foo", nil); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	ctx = WithSchema(ctx, "analytics")
+	if _, err := c.ExecContext(ctx, "DELETE FROM foo", nil); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	s, err := c.PrepareContext(ctx, "INSERT INTO foo VALUES (:id)")
+	if err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+
+	if _, err = s.(*Stmt).ExecContext(ctx, []driver.NamedValue{{Name: "id", Value: int64(1)}}); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if err = s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	if aws.StringValue(m.executes[0].Schema) != "public" || aws.StringValue(m.executes[1].Schema) != "analytics" {
+		t.Fatalf("unexpected schemas, got: %v and %v", m.executes[0].Schema, m.executes[1].Schema)
+	}
+
+	if aws.StringValue(m.batches[0].Schema) != "analytics" {
+		t.Fatalf("expected batch to use schema from context, got: %v", m.batches[0].Schema)
+	}
+
+	if _, err = c.BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	if err = c.Rollback(); err != nil {
+		t.Fatalf("failed to rollback: %v", err)
+	}
+
+	if _, err = c.BeginTx(ctx, driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	if aws.StringValue(m.begins[0].Schema) != "public" || aws.StringValue(m.begins[1].Schema) != "analytics" {
+		t.Fatalf("expected transactions to use the schema, got: %v and %v", m.begins[0].Schema, m.begins[1].Schema)
+	}
+
+	c.cfg.Schema = ""
+	c.Rollback()
+	if _, err = c.BeginTx(context.Background(), driver.TxOptions{}); err != nil || m.begins[2].Schema != nil {
+		t.Fatalf("expected no schema without one configured, got: %v (%v)", m.begins[2].Schema, err)
+	}
+}
+
+func TestReaderFromContext(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+	c.cfg.ReaderResourceARN = "arn:reader"
+
+	ctx := WithReader(context.Background())
+	if _, err := c.QueryContext(ctx, "SELECT 1", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if _, err := c.ExecContext(ctx, "DELETE FROM foo", nil); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if _, err := c.BeginTx(ctx, driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	if _, err := c.QueryContext(ctx, "SELECT 1", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if _, err := c.QueryContext(context.Background(), "SELECT 1", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	for i, exp := range []string{"arn:reader", "arn:res", "arn:res", "arn:res"} {
+		if act := aws.StringValue(m.executes[i].ResourceArn); act != exp {
+			t.Fatalf("expected statement %d to use resource '%s', got: '%s'", i, exp, act)
+		}
+	}
+}
+
+func TestResourceFromContext(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+	c.cfg.ReaderResourceARN = "arn:reader"
+
+	other := WithResource(WithReader(context.Background()), "arn:other", "arn:other-sec")
+	if _, err := c.QueryContext(other, "SELECT 1", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if in := m.executes[0]; aws.StringValue(in.ResourceArn) != "arn:other" || aws.StringValue(in.SecretArn) != "arn:other-sec" {
+		t.Fatalf("expected the resource from the context to be used, got: %v", in)
+	}
+
+	if _, err := c.BeginTx(other, driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	if aws.StringValue(m.begins[0].ResourceArn) != "arn:other" {
+		t.Fatalf("expected transaction to begin on the other resource, got: %v", m.begins[0])
+	}
+
+	if _, err := c.ExecContext(context.Background(), "DELETE FROM foo WHERE id = 1", nil); err == nil {
+		t.Fatalf("expected error for a statement on another resource than the transaction")
+	}
+
+	if _, err := c.ExecContext(other, "DELETE FROM foo WHERE id = 1", nil); err != nil {
+		t.Fatalf("failed to exec in transaction: %v", err)
+	}
+
+	if err := c.Commit(); err != nil || aws.StringValue(m.commits[0].ResourceArn) != "arn:other" {
+		t.Fatalf("expected commit on the other resource, got: %v (%v)", m.commits, err)
+	}
+
+	if _, err := c.ExecContext(context.Background(), "DELETE FROM foo WHERE id = 1", nil); err != nil {
+		t.Fatalf("failed to exec after the transaction: %v", err)
+	}
+
+	if act := aws.StringValue(m.executes[len(m.executes)-1].ResourceArn); act != "arn:res" {
+		t.Fatalf("expected the connection's resource after the transaction, got: %s", act)
+	}
+
+	st, err := c.PrepareContext(other, "INSERT INTO foo (id) VALUES (:id)")
+	if err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+
+	if _, err = st.(*Stmt).ExecContext(context.Background(), []driver.NamedValue{{Name: "id", Value: int64(1)}}); err != nil {
+		t.Fatalf("failed to exec statement: %v", err)
+	}
+
+	if err = st.Close(); err != nil || aws.StringValue(m.batches[0].ResourceArn) != "arn:other" {
+		t.Fatalf("expected batch on the resource it was prepared with, got: %v (%v)", m.batches, err)
+	}
+}
+
+func TestResultMetadataFromContext(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+
+	bg := context.Background()
+	for i, q := range []struct {
+		ctx   context.Context
+		query string
+		exp   bool
+	}{
+		{bg, "INSERT INTO foo VALUES (1)", false},
+		{WithResultMetadata(bg, true), "INSERT INTO foo VALUES (1)", true},
+		{bg, "CALL proc()", true},
+		{WithResultMetadata(bg, false), "CALL proc()", false},
+	} {
+		if _, err := c.ExecContext(q.ctx, q.query, nil); err != nil {
+			t.Fatalf("%d: failed to exec: %v", i, err)
+		}
+
+		if act := aws.BoolValue(m.executes[i].IncludeResultMetadata); act != q.exp {
+			t.Fatalf("%d: expected result metadata to be %v, got: %v", i, q.exp, act)
+		}
+	}
+}
+
+func TestRawParamsFromContext(t *testing.T) {
+	m := &mockAPI{}
+	db := mockDB(t, m)
+
+	raw := []*rdsds.SqlParameter{{Name: aws.String("d"), TypeHint: aws.String(rdsds.TypeHintDecimal), Value: &rdsds.Field{StringValue: aws.String("1.50")}}}
+	ctx := WithRawParams(context.Background(), raw)
+	if _, err := db.ExecContext(ctx, "INSERT INTO foo VALUES (:d)"); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if ps := m.executes[0].Parameters; len(ps) != 1 || ps[0] != raw[0] {
+		t.Fatalf("expected raw params to be sent as-is, got: %v", ps)
+	}
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO foo VALUES (:d)", sql.Named("d", 1)); err == nil {
+		t.Fatalf("expected error for arguments as well as raw params")
+	}
+}
@@ -0,0 +1,59 @@
+package rdsdataapi
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Decimal holds the exact string representation of a DECIMAL (or NUMERIC) value. Configure
+// 'DecimalValues' to have such columns returned as a Decimal, they can then be scanned into
+// a string, float64 or Decimal alike. Scanning into an integer only works for values
+// without a fraction. As an argument a Decimal is sent as a string with the DECIMAL type
+// hint, such that no precision is lost.
+type Decimal string
+
+// String returns the exact representation of the decimal
+func (d Decimal) String() string { return string(d) }
+
+// Float64 returns the float64 that is nearest to the decimal
+func (d Decimal) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(string(d), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse decimal '%s': %w", d, err)
+	}
+
+	return f, nil
+}
+
+// Scan implements sql.Scanner, it accepts the values of decimal, string, float and integer
+// columns. NULL can't be scanned into a Decimal, scan into a *Decimal instead.
+func (d *Decimal) Scan(src interface{}) error {
+	switch t := src.(type) {
+	case Decimal:
+		*d = t
+	case string:
+		*d = Decimal(t)
+	case []byte:
+		*d = Decimal(t)
+	case float64:
+		*d = Decimal(strconv.FormatFloat(t, 'f', -1, 64))
+	case int64:
+		*d = Decimal(strconv.FormatInt(t, 10))
+	case nil:
+		return fmt.Errorf("can't scan NULL into a Decimal, scan into a *Decimal instead")
+	default:
+		return fmt.Errorf("can't scan a value of type %T into a Decimal", src)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, it returns the decimal as a string
+func (d Decimal) Value() (driver.Value, error) { return string(d), nil }
+
+// isDecimalType returns whether the (upper-cased) type name is an exact numeric type
+func isDecimalType(typ string) bool {
+	return strings.HasPrefix(typ, "DECIMAL") || strings.HasPrefix(typ, "NUMERIC")
+}
@@ -0,0 +1,62 @@
+package rdsdataapi
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestDecimalValues(t *testing.T) {
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("a"), TypeName: aws.String("DECIMAL")},
+				{Name: aws.String("b"), TypeName: aws.String("numeric")}, {Name: aws.String("c"), TypeName: aws.String("DECIMAL")}},
+			Records: [][]*rdsds.Field{{{StringValue: aws.String("12.345678901234567890")}, {StringValue: aws.String("42")}, {IsNull: aws.Bool(true)}}},
+		}, nil
+	}}
+
+	cfg := testCfg
+	cfg.DecimalValues = true
+	db := sql.OpenDB(newConnector(cfg, m))
+
+	var (
+		s string
+		f float64
+		d Decimal
+		i int64
+		n *Decimal
+	)
+
+	for _, dest := range [][]interface{}{{&s, &i, &n}, {&f, &d, &n}, {&d, &f, &n}} {
+		if err := db.QueryRow("SELECT a, b, c FROM foo").Scan(dest...); err != nil {
+			t.Fatalf("failed to scan into %T %T: %v", dest[0], dest[1], err)
+		}
+	}
+
+	if s != "12.345678901234567890" || i != 42 || n != nil || f != 42 || d != "12.345678901234567890" {
+		t.Fatalf("unexpected scanned values: '%s' %d %v %v '%s'", s, i, n, f, d)
+	}
+
+	if af, err := d.Float64(); err != nil || af != 12.345678901234567 {
+		t.Fatalf("expected nearest float, got: %v (%v)", af, err)
+	}
+
+	if _, err := db.Exec("UPDATE foo SET a = :a", sql.Named("a", Decimal("0.10"))); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	p := m.executes[len(m.executes)-1].Parameters[0]
+	if aws.StringValue(p.TypeHint) != rdsds.TypeHintDecimal || aws.StringValue(p.Value.StringValue) != "0.10" {
+		t.Fatalf("expected decimal argument to be sent with its hint, got: %v", p)
+	}
+
+	if err := d.Scan(nil); err == nil {
+		t.Fatalf("expected error scanning NULL into a Decimal")
+	}
+
+	if _, err := NewConnector(Config{Database: "db", ResourceARN: "arn:res", SecretARN: "arn:sec", DecimalValues: true, DecimalReturnType: rdsds.DecimalReturnTypeDoubleOrLong}); err == nil {
+		t.Fatalf("expected error combining DecimalValues with DOUBLE_OR_LONG")
+	}
+}
@@ -0,0 +1,181 @@
+package rdsdataapi
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+const (
+	// ZeroDateError makes decoding a zero date ('0000-00-00') fail
+	ZeroDateError = "error"
+
+	// ZeroDateNull decodes a zero date as NULL
+	ZeroDateNull = "null"
+
+	// ZeroDateZeroTime decodes a zero date as the zero time.Time
+	ZeroDateZeroTime = "zeroTime"
+)
+
+// decodeColumn decodes the field of column i of the results, using the column's metadata
+// to convert the value for types the Data API has no dedicated field for.
+func (r *Rows) decodeColumn(i int, f *rdsds.Field) (v interface{}, err error) {
+	if v, err = decodeField(f); err != nil || v == nil || i >= len(r.output.ColumnMetadata) {
+		return
+	}
+
+	typ := strings.ToUpper(aws.StringValue(r.output.ColumnMetadata[i].TypeName))
+	switch t := v.(type) {
+	case string:
+		if r.cfg.ParseTime && isTimeType(typ) {
+			return parseTime(t, r.cfg.ZeroDateHandling)
+		}
+
+		// as bytes, the sql package can't scan a json.RawMessage value into a *string
+		if typ == "JSON" || typ == "JSONB" {
+			return []byte(t), nil
+		}
+
+		if r.cfg.DecimalValues && isDecimalType(typ) {
+			return Decimal(t), nil
+		}
+
+		if typ == "BYTEA" && strings.HasPrefix(t, `\x`) {
+			return decodeByteaHex(t)
+		}
+	case []byte:
+		if len(t) == 16 && r.isUUIDColumn(i) {
+			var u UUID
+			copy(u[:], t)
+			return u.String(), nil
+		}
+	case int64:
+		if !r.cfg.TinyIntAsInt && isBoolType(typ, r.output.ColumnMetadata[i]) {
+			return t != 0, nil
+		}
+
+		if r.cfg.NumericAsString {
+			return strconv.FormatInt(t, 10), nil
+		}
+	case float64:
+		if r.cfg.NumericAsString {
+			return strconv.FormatFloat(t, 'g', -1, 64), nil
+		}
+	}
+
+	return v, nil
+}
+
+// decodeByteaHex decodes a postgres BYTEA in its hex output format ('\x' followed by two
+// hex digits per byte), as the Data API may return it as a string instead of as a blob.
+func decodeByteaHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode BYTEA value in hex format: %w", err)
+	}
+
+	return b, nil
+}
+
+// isUUIDColumn returns whether column i is configured to hold binary UUIDs, by its name or
+// its label.
+func (r *Rows) isUUIDColumn(i int) bool {
+	meta := r.output.ColumnMetadata[i]
+	for _, col := range r.cfg.UUIDColumns {
+		if col == aws.StringValue(meta.Name) || col == aws.StringValue(meta.Label) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBoolType returns whether the column holds booleans, MySQL stores a BOOLEAN as TINYINT(1)
+func isBoolType(typ string, meta *rdsds.ColumnMetadata) bool {
+	return typ == "BOOL" || typ == "BOOLEAN" || (typ == "TINYINT" && aws.Int64Value(meta.Precision) == 1)
+}
+
+// isTimeType returns whether the (upper-cased) type name is a date and/or time-of-day type
+func isTimeType(typ string) bool {
+	switch typ {
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseTime parses a DATE, DATETIME or TIMESTAMP value as returned by the Data API, in UTC.
+// MySQL's zero dates can't be represented by time.Time and are handled as configured.
+func parseTime(s string, zeroDate string) (v interface{}, err error) {
+	if strings.HasPrefix(s, "0000-00-00") {
+		switch zeroDate {
+		case ZeroDateNull:
+			return nil, nil
+		case ZeroDateZeroTime:
+			return time.Time{}, nil
+		default:
+			return nil, fmt.Errorf("zero date '%s' can't be represented as time.Time, consider the 'ZeroDateHandling' option", s)
+		}
+	}
+
+	layout := "2006-01-02 15:04:05" // fractional seconds are accepted when parsing
+	if len(s) == len(dateFormat) {
+		layout = dateFormat
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse '%s' as time: %w", s, err)
+	}
+
+	return t, nil
+}
+
+// timeLayouts are the layouts that ScanTime recognizes, fractional seconds are optional
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999", // DATETIME and TIMESTAMP, as returned by the Data API
+	time.RFC3339Nano,
+	dateFormat,
+	"15:04:05.999999999", // TIME, on the zero date
+}
+
+// ScanTime returns a sql.Scanner that scans a column into dest, for a date and/or time that
+// is returned as a string because ParseTime is not enabled, e.g: rows.Scan(ScanTime(&t)).
+// The sql package itself can't scan a string into a *time.Time. Strings are parsed, in UTC,
+// with the first matching layout of: '2006-01-02 15:04:05[.999999999]', RFC 3339,
+// '2006-01-02' and '15:04:05[.999999999]'. Other strings can't be scanned and result in an
+// error, time.Time values are stored as-is and NULL results in the zero time.
+func ScanTime(dest *time.Time) sql.Scanner { return (*timeScanner)(dest) }
+
+// timeScanner implements the scanner returned by ScanTime
+type timeScanner time.Time
+
+// Scan implements sql.Scanner
+func (ts *timeScanner) Scan(src interface{}) error {
+	switch t := src.(type) {
+	case nil:
+		*ts = timeScanner{}
+	case time.Time:
+		*ts = timeScanner(t)
+	case string:
+		for _, layout := range timeLayouts {
+			if tm, err := time.Parse(layout, t); err == nil {
+				*ts = timeScanner(tm)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("failed to scan '%s' as time: it doesn't match any of the supported layouts", t)
+	default:
+		return fmt.Errorf("failed to scan %T as time", src)
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// decodeRow decodes a single record with the given column type names using cfg
+func decodeRow(cfg Config, types []string, rec []*rdsds.Field) ([]driver.Value, error) {
+	out := &rdsds.ExecuteStatementOutput{Records: [][]*rdsds.Field{rec}}
+	for _, typ := range types {
+		out.ColumnMetadata = append(out.ColumnMetadata, &rdsds.ColumnMetadata{Name: aws.String(typ), TypeName: aws.String(typ)})
+	}
+
+	dest := make([]driver.Value, len(rec))
+	return dest, (&Rows{output: out, cfg: cfg}).Next(dest)
+}
+
+func TestDecodeParseTime(t *testing.T) {
+	types := []string{"DATE", "DATETIME", "timestamp", "VARCHAR"}
+	rec := []*rdsds.Field{
+		{StringValue: aws.String("2020-02-15")},
+		{StringValue: aws.String("2020-02-15 13:04:05")},
+		{StringValue: aws.String("2020-02-15 13:04:05.12")},
+		{StringValue: aws.String("2020-02-15")},
+	}
+
+	dest, err := decodeRow(Config{}, types, rec)
+	if err != nil || dest[0] != "2020-02-15" {
+		t.Fatalf("expected strings without ParseTime, got: %v (%v)", dest, err)
+	}
+
+	dest, err = decodeRow(Config{ParseTime: true}, types, rec)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	for i, exp := range []interface{}{
+		time.Date(2020, 2, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 2, 15, 13, 4, 5, 0, time.UTC),
+		time.Date(2020, 2, 15, 13, 4, 5, 120000000, time.UTC),
+		"2020-02-15",
+	} {
+		if dest[i] != exp {
+			t.Fatalf("%d: expected %v, got: %v", i, exp, dest[i])
+		}
+	}
+}
+
+func TestDecodeZeroDate(t *testing.T) {
+	rec := []*rdsds.Field{{StringValue: aws.String("0000-00-00 00:00:00")}}
+	for _, c := range []struct {
+		mode   string
+		exp    interface{}
+		expErr bool
+	}{
+		{"", nil, true},
+		{ZeroDateError, nil, true},
+		{ZeroDateNull, nil, false},
+		{ZeroDateZeroTime, time.Time{}, false},
+	} {
+		dest, err := decodeRow(Config{ParseTime: true, ZeroDateHandling: c.mode}, []string{"DATETIME"}, rec)
+		if (err != nil) != c.expErr {
+			t.Fatalf("%s: expected error to be %v, got: %v", c.mode, c.expErr, err)
+		}
+
+		if !c.expErr && dest[0] != c.exp {
+			t.Fatalf("%s: expected %v, got: %v", c.mode, c.exp, dest[0])
+		}
+	}
+}
+
+func TestDecodeNumericAsString(t *testing.T) {
+	types := []string{"BIGINT", "BIGINT", "DECIMAL", "DOUBLE"}
+	rec := []*rdsds.Field{
+		{LongValue: aws.Int64(math.MaxInt64)},
+		{LongValue: aws.Int64(math.MinInt64)},
+		{StringValue: aws.String("12345678901234567890.123456789")},
+		{DoubleValue: aws.Float64(0.1)},
+	}
+
+	dest, err := decodeRow(Config{}, types, rec)
+	if err != nil || dest[0] != int64(math.MaxInt64) || dest[3] != 0.1 {
+		t.Fatalf("expected native types by default, got: %v (%v)", dest, err)
+	}
+
+	dest, err = decodeRow(Config{NumericAsString: true}, types, rec)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if !reflect.DeepEqual(dest, []driver.Value{"9223372036854775807", "-9223372036854775808", "12345678901234567890.123456789", "0.1"}) {
+		t.Fatalf("unexpected exact strings, got: %v", dest)
+	}
+
+	m := &mockAPI{}
+	cfg := testCfg
+	cfg.NumericAsString = true
+	conn, err := newConnector(cfg, m).Connect(context.Background())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	if _, err = conn.(*Conn).QueryContext(context.Background(), "SELECT 1.5", nil); err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	if act := aws.StringValue(m.executes[0].ResultSetOptions.DecimalReturnType); act != rdsds.DecimalReturnTypeString {
+		t.Fatalf("expected decimals to be requested as strings, got: %v", act)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	doc := `{"foo":[1,"bar"]}`
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if aws.StringValue(in.Sql) != "SELECT doc, doc, doc FROM docs" {
+			return &rdsds.ExecuteStatementOutput{}, nil
+		}
+
+		meta := &rdsds.ColumnMetadata{Name: aws.String("doc"), TypeName: aws.String("jsonb")}
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{meta, meta, meta},
+			Records:        [][]*rdsds.Field{{{StringValue: aws.String(doc)}, {StringValue: aws.String(doc)}, {StringValue: aws.String(doc)}}},
+		}, nil
+	}}
+
+	db := mockDB(t, m)
+	if _, err := db.Exec("INSERT INTO docs VALUES (:doc)", sql.Named("doc", doc)); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	var (
+		raw json.RawMessage
+		s   string
+		v   interface{}
+	)
+
+	if err := db.QueryRow("SELECT doc, doc, doc FROM docs").Scan(&raw, &s, &v); err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+
+	if string(raw) != doc || s != doc {
+		t.Fatalf("expected the json document to round-trip, got: %s and %s", raw, s)
+	}
+
+	if b, ok := v.([]byte); !ok || !json.Valid(b) {
+		t.Fatalf("expected json bytes, got: %T", v)
+	}
+}
+
+func TestDecodeTinyIntAsBool(t *testing.T) {
+	out := &rdsds.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsds.ColumnMetadata{
+			{Name: aws.String("a"), TypeName: aws.String("TINYINT"), Precision: aws.Int64(1)},
+			{Name: aws.String("b"), TypeName: aws.String("TINYINT"), Precision: aws.Int64(1)},
+			{Name: aws.String("c"), TypeName: aws.String("TINYINT"), Precision: aws.Int64(4)},
+		},
+		Records: [][]*rdsds.Field{{{LongValue: aws.Int64(1)}, {LongValue: aws.Int64(0)}, {LongValue: aws.Int64(1)}}},
+	}
+
+	dest := make([]driver.Value, 3)
+	if err := (&Rows{output: out}).Next(dest); err != nil || !reflect.DeepEqual(dest, []driver.Value{true, false, int64(1)}) {
+		t.Fatalf("expected TINYINT(1) columns to be bools, got: %v (%v)", dest, err)
+	}
+
+	dest = make([]driver.Value, 3)
+	if err := (&Rows{output: out, cfg: Config{TinyIntAsInt: true}}).Next(dest); err != nil || !reflect.DeepEqual(dest, []driver.Value{int64(1), int64(0), int64(1)}) {
+		t.Fatalf("expected integers with TinyIntAsInt, got: %v (%v)", dest, err)
+	}
+}
+
+func TestScanTime(t *testing.T) {
+	db := mockDB(t, &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("a")}, {Name: aws.String("b")}, {Name: aws.String("c")}, {Name: aws.String("d")}},
+			Records: [][]*rdsds.Field{{
+				{StringValue: aws.String("2020-02-15 13:04:05.12")},
+				{StringValue: aws.String("2020-02-15")},
+				{IsNull: aws.Bool(true)},
+				{StringValue: aws.String("not a time")},
+			}},
+		}, nil
+	}})
+
+	var a, b, c time.Time
+	var d string
+	if err := db.QueryRow("SELECT a, b, c, d FROM foo").Scan(ScanTime(&a), ScanTime(&b), ScanTime(&c), &d); err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+
+	if !a.Equal(time.Date(2020, 2, 15, 13, 4, 5, 120000000, time.UTC)) || !b.Equal(time.Date(2020, 2, 15, 0, 0, 0, 0, time.UTC)) || !c.IsZero() {
+		t.Fatalf("unexpected times, got: %v, %v and %v", a, b, c)
+	}
+
+	if err := db.QueryRow("SELECT a, b, c, d FROM foo").Scan(&d, &d, &d, ScanTime(&a)); err == nil {
+		t.Fatalf("expected error for an unrecognized layout")
+	}
+}
+
+func TestDecodeByteaHex(t *testing.T) {
+	blob := []byte{0x00, 0xde, 0xad, 0xbe, 0xef}
+	dest, err := decodeRow(Config{}, []string{"bytea", "bytea", "text"}, []*rdsds.Field{
+		{StringValue: aws.String(`\x00deadbeef`)},
+		{BlobValue: blob},
+		{StringValue: aws.String(`\x00deadbeef`)},
+	})
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if !reflect.DeepEqual(dest, []driver.Value{blob, blob, `\x00deadbeef`}) {
+		t.Fatalf("expected hex strings of BYTEA columns to be decoded into bytes, got: %v", dest)
+	}
+
+	if _, err = decodeRow(Config{}, []string{"bytea"}, []*rdsds.Field{{StringValue: aws.String(`\xzz`)}}); err == nil {
+		t.Fatalf("expected an error for invalid hex")
+	}
+}
@@ -6,15 +6,23 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"io"
-	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/request"
 	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
 )
 
+// drv is the driver that is registered with the sql package, connectors return it as well
+var drv = &Driver{}
+
 func init() {
-	sql.Register("rds-data-api", &Driver{})
+	sql.Register("rds-data-api", drv)
 }
 
 type Driver struct{}
@@ -23,38 +31,55 @@ func (d *Driver) Open(s string) (_ driver.Conn, err error) {
 	return Open(s)
 }
 
+// OpenConnector parses the connection string once so it can be re-used for every
+// new connection that is initiated by the sql package.
+func (d *Driver) OpenConnector(s string) (_ driver.Connector, err error) {
+	cfg, err := ParseDSN(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnector(cfg)
+}
+
+// dataAPI describes the part of the AWS RDS Data API that is used by the driver, it
+// is implemented by *rdsds.RDSDataService and allows it to be mocked in tests.
+type dataAPI interface {
+	ExecuteStatementWithContext(aws.Context, *rdsds.ExecuteStatementInput, ...request.Option) (*rdsds.ExecuteStatementOutput, error)
+	BatchExecuteStatementWithContext(aws.Context, *rdsds.BatchExecuteStatementInput, ...request.Option) (*rdsds.BatchExecuteStatementOutput, error)
+	BeginTransactionWithContext(aws.Context, *rdsds.BeginTransactionInput, ...request.Option) (*rdsds.BeginTransactionOutput, error)
+	CommitTransactionWithContext(aws.Context, *rdsds.CommitTransactionInput, ...request.Option) (*rdsds.CommitTransactionOutput, error)
+	RollbackTransactionWithContext(aws.Context, *rdsds.RollbackTransactionInput, ...request.Option) (*rdsds.RollbackTransactionOutput, error)
+}
+
 // Conn is a connection to a database. It is not used concurrently by multiple goroutines.
 type Conn struct {
-	closed         bool                  // whether the conn has been blosed
-	databaseName   string                // name of the database on which queries will be performed
-	resourceARN    string                // the aws resource accesses with this conn
-	secretARN      string                // the aws secret that provides access to the resource
-	rdsDataService *rdsds.RDSDataService // AWS RDS data service API
-	transactionID  string                // the id of a transaction if one was started
+	closed         bool     // whether the conn has been blosed
+	databaseName   string   // name of the database on which queries will be performed
+	resourceARN    string   // the aws resource accesses with this conn
+	secretARN      string   // the aws secret that provides access to the resource
+	rdsDataService dataAPI  // AWS RDS data service API
+	transactionID  string   // the id of a transaction if one was started
+	txResource     resource // the resource from WithResource the transaction began with, if any
+	cfg            Config   // configuration of the connector that created this conn
+	retryer        *retryer
+	connector      *Connector // the connector that created this conn
+
+	txCtx context.Context // the context the current transaction began with
 }
 
 func Open(q string) (_ driver.Conn, err error) {
-	cfg, err := url.ParseQuery(q)
+	cfg, err := ParseDSN(q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse conn string as url query: %w", err) // @TODO test
-	}
-
-	sess := session.New()
-
-	c := &Conn{
-		databaseName: cfg.Get("Database"),
-		resourceARN:  cfg.Get("ResourceARN"),
-		secretARN:    cfg.Get("SecretARN"),
-
-		// @TODO don't hardcode region, but does that mean user need to be able to pass other configs as well?
-		rdsDataService: rdsds.New(sess, aws.NewConfig().WithRegion("eu-west-1")),
+		return nil, err // @TODO test
 	}
 
-	if c.resourceARN == "" || c.secretARN == "" || c.databaseName == "" {
-		return nil, fmt.Errorf("required configuration value 'Database', 'ResourceARN' or 'SecretARN' are missing") // @TODO test
+	c, err := NewConnector(cfg)
+	if err != nil {
+		return nil, err // @TODO test
 	}
 
-	return c, err
+	return c.Connect(context.Background())
 }
 
 // PrepareContext returns a prepared statement, bound to this connection.
@@ -62,10 +87,24 @@ func Open(q string) (_ driver.Conn, err error) {
 // it must not store the context within the statement itself.
 func (c *Conn) PrepareContext(ctx context.Context, query string) (_ driver.Stmt, err error) {
 	if c.rdsDataService == nil {
-		return nil, fmt.Errorf("connection already closed") //@TODO test
+		return nil, fmt.Errorf("failed to prepare statement: %w", ErrConnClosed)
+	}
+
+	a := c.analyze(query)
+	if err = c.guardReadOnly(ctx, a.kind); err != nil {
+		return nil, err
+	}
+
+	if err = c.guardWrite(ctx, query, a); err != nil {
+		return nil, err
+	}
+
+	st := &Stmt{query: query, conn: c, schema: c.schema(ctx)}
+	if r, ok := resourceFromContext(ctx); ok {
+		st.res = &r
 	}
 
-	return &Stmt{query: query, conn: c}, nil
+	return st, nil
 }
 
 // BeginTx starts and returns a new transaction.
@@ -80,66 +119,171 @@ func (c *Conn) PrepareContext(ctx context.Context, query string) (_ driver.Stmt,
 // This must also check opts.ReadOnly to determine if the read-only
 // value is true to either set the read-only transaction property if supported
 // or return an error if it is not supported.
-func (c *Conn) BeginTx(ctx context.Context, opts sql.TxOptions) (_ driver.Tx, err error) {
+func (c *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (_ driver.Tx, err error) {
 	if c.rdsDataService == nil {
-		return nil, fmt.Errorf("connection already closed") //@TODO test
+		return nil, driver.ErrBadConn // allows the sql package to retry with another conn
+	}
+
+	if sql.IsolationLevel(opts.Isolation) != sql.LevelDefault {
+		return nil, fmt.Errorf("the Data API doesn't support setting the isolation level of a transaction") //@TODO test
+	}
+
+	if opts.ReadOnly && !c.cfg.ReadOnlyConnection {
+		return nil, fmt.Errorf("the Data API doesn't support read-only transactions") //@TODO test
+	}
+
+	if !opts.ReadOnly && c.cfg.ReadOnlyConnection {
+		return nil, fmt.Errorf("%w: transactions must be read-only, begin them with sql.TxOptions{ReadOnly: true}", ErrReadOnly)
 	}
 
 	if c.transactionID != "" {
-		return nil, fmt.Errorf("a transaction already started") //@TODO test
+		return nil, fmt.Errorf("transaction '%s' already started on this connection, it must be committed or rolled back before starting another", c.transactionID)
 	}
 
-	var out *rdsds.BeginTransactionOutput
-	if out, err = c.rdsDataService.BeginTransactionWithContext(ctx, &rdsds.BeginTransactionInput{
-		// Schema: @TODO add schema support
+	resourceARN, secretARN, err := c.resourceFor(ctx, StatementOther)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &rdsds.BeginTransactionInput{
 		Database:    aws.String(c.databaseName),
-		ResourceArn: aws.String(c.resourceARN),
-		SecretArn:   aws.String(c.secretARN),
-	}); err != nil {
-		return nil, fmt.Errorf("failed to being transaction: %w", err)
+		ResourceArn: aws.String(resourceARN),
+		SecretArn:   aws.String(secretARN),
+	}
+
+	if schema := c.schema(ctx); schema != "" {
+		in.SetSchema(schema)
+	}
+
+	// the transaction keeps ctx, only beginning it is bounded by the TxBeginTimeout
+	bctx, cancel := c.withDefaultTimeout(ctx, c.cfg.TxBeginTimeout)
+	defer cancel()
+
+	var out *rdsds.BeginTransactionOutput
+	start := c.clock().Now()
+	err = c.retryer.do(bctx, func() (err error) {
+		out, err = c.rdsDataService.BeginTransactionWithContext(bctx, in)
+		return
+	})
+	if c.observe(OpBegin, start, err); err != nil {
+		return nil, fmt.Errorf("failed to being transaction: %w", c.checkAccessDenied(err))
 	}
 
 	c.transactionID = aws.StringValue(out.TransactionId)
+	c.txCtx = ctx
+	c.txResource, _ = resourceFromContext(ctx)
+
+	// the Data API only keeps the session for the duration of a transaction
+	if c.cfg.StatementTimeout > 0 {
+		if err = c.setStatementTimeout(bctx); err != nil {
+			if rerr := c.Rollback(); rerr != nil {
+				return nil, fmt.Errorf("%v, and then failed to rollback transaction: %w", err, rerr)
+			}
+
+			return nil, err
+		}
+	}
+
 	return c, nil
 }
 
+// withTxCancel returns a context derived from ctx that is also cancelled once the context the
+// current transaction began with is done. The sql package only rolls back when that happens,
+// so this aborts a statement that is in flight instead of making the rollback wait for it.
+func (c *Conn) withTxCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if c.txCtx == nil || c.txCtx.Done() == nil {
+		return ctx, cancel
+	}
+
+	done := c.txCtx.Done()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// txContext returns the context for committing or rolling back the transaction. The
+// sql package doesn't provide one, so the context the transaction began with is used
+// while it is still valid. Either way the operation is bounded by the timeout, or the
+// OperationTimeout if it is not configured.
+func (c *Conn) txContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if c.txCtx != nil && c.txCtx.Err() == nil {
+		return withTimeout(c.txCtx, c.clock(), c.cfg.orOperationTimeout(timeout))
+	}
+
+	return withTimeout(context.Background(), c.clock(), c.cfg.orOperationTimeout(timeout))
+}
+
+// withDefaultTimeout bounds ctx by the timeout, or the OperationTimeout if it is not
+// configured, unless the caller already provided a context with a deadline.
+func (c *Conn) withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return withTimeout(ctx, c.clock(), c.cfg.orOperationTimeout(timeout))
+}
+
 func (c *Conn) Commit() (err error) {
 	if c.transactionID == "" {
-		return fmt.Errorf("no open transaction to commit") //@TODO test
+		return fmt.Errorf("failed to commit: %w", ErrNoTransaction)
 	}
 
-	// @TODO do we want to allow the user the option to configure a timeout?
-	ctx := context.Background()
-
-	if _, err = c.rdsDataService.CommitTransactionWithContext(ctx, &rdsds.CommitTransactionInput{
-		TransactionId: aws.String(c.transactionID),
-		ResourceArn:   aws.String(c.resourceARN),
-		SecretArn:     aws.String(c.secretARN),
-	}); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	ctx, cancel := c.txContext(c.cfg.TxCommitTimeout)
+	defer cancel()
+
+	resourceARN, secretARN := c.txARNs()
+	start := c.clock().Now()
+	err = c.retryer.do(ctx, func() (err error) {
+		_, err = c.rdsDataService.CommitTransactionWithContext(ctx, &rdsds.CommitTransactionInput{
+			TransactionId: aws.String(c.transactionID),
+			ResourceArn:   aws.String(resourceARN),
+			SecretArn:     aws.String(secretARN),
+		})
+		return
+	})
+	if c.observe(OpCommit, start, err); err != nil {
+		return c.txError("commit", err)
 	}
 
-	c.transactionID = ""
+	c.transactionID, c.txCtx, c.txResource = "", nil, resource{}
 	return
 }
 
 func (c *Conn) Rollback() (err error) {
 	if c.transactionID == "" {
-		return fmt.Errorf("no open transaction to rollback") //@TODO test
+		return fmt.Errorf("failed to rollback: %w", ErrNoTransaction)
 	}
 
-	// @TODO do we want to allow the user the option to configure a timeout here?
-	ctx := context.Background()
+	ctx, cancel := c.txContext(c.cfg.TxRollbackTimeout)
+	defer cancel()
 
-	if _, err = c.rdsDataService.RollbackTransactionWithContext(ctx, &rdsds.RollbackTransactionInput{
-		TransactionId: aws.String(c.transactionID),
-		ResourceArn:   aws.String(c.resourceARN),
-		SecretArn:     aws.String(c.secretARN),
-	}); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	return c.rollback(ctx)
+}
+
+// rollback rolls back the current transaction with the provided context
+func (c *Conn) rollback(ctx context.Context) (err error) {
+	resourceARN, secretARN := c.txARNs()
+	start := c.clock().Now()
+	err = c.retryer.do(ctx, func() (err error) {
+		_, err = c.rdsDataService.RollbackTransactionWithContext(ctx, &rdsds.RollbackTransactionInput{
+			TransactionId: aws.String(c.transactionID),
+			ResourceArn:   aws.String(resourceARN),
+			SecretArn:     aws.String(secretARN),
+		})
+		return
+	})
+	if c.observe(OpRollback, start, err); err != nil {
+		return c.txError("rollback", err)
 	}
 
-	c.transactionID = ""
+	c.transactionID, c.txCtx, c.txResource = "", nil, resource{}
 	return
 }
 
@@ -151,73 +295,340 @@ func (c *Conn) Rollback() (err error) {
 // connections and only calls Close when there's a surplus of
 // idle connections, it shouldn't be necessary for drivers to
 // do their own connection caching.
-func (c *Conn) Close() (err error) { c.rdsDataService = nil; return }
+//
+// A transaction that is still open is rolled back on a best-effort basis, bounded by the
+// TxRollbackTimeout. If that fails it is logged and the connection is closed regardless, so
+// a slow or unavailable Data API doesn't block the pool.
+func (c *Conn) Close() (err error) {
+	if c.transactionID != "" && c.rdsDataService != nil {
+		ctx, cancel := withTimeout(context.Background(), c.clock(), c.cfg.orOperationTimeout(c.cfg.TxRollbackTimeout))
+		defer cancel()
+
+		if id := c.transactionID; c.rollback(ctx) != nil {
+			c.logf("failed to rollback transaction '%s' while closing the connection, it will time out on its own", id)
+		}
+	}
 
+	c.rdsDataService, c.transactionID, c.txCtx = nil, "", nil
+	return
+}
+
+// Ping checks that the Data API can be reached by executing the ValidationQuery, it is
+// called by the sql package's Ping.
+func (c *Conn) Ping(ctx context.Context) error {
+	if c.rdsDataService == nil {
+		return driver.ErrBadConn
+	}
+
+	if _, err := c.execute(ctx, c.cfg.validationQuery(), nil); err != nil {
+		return fmt.Errorf("failed to ping: %w", err)
+	}
+
+	return nil
+}
+
+// IsValid is called by the sql package before a connection is reused. Executing the
+// ValidationQuery for every reuse would double the calls to the Data API, which is
+// stateless anyway, so this only reports whether the connection was closed. Use Ping or
+// Check to verify that the Data API can be reached.
+func (c *Conn) IsValid() bool { return c.rdsDataService != nil }
+
+// ExecContext executes the statement with a single call to the Data API. As the conn
+// implements driver.ExecerContext the sql package calls it directly, without preparing a
+// statement first: the Data API has no prepare step that would make a Stmt worthwhile.
 func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Result, err error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, c.cfg.ExecTimeout)
+	defer cancel()
+
+	query, returning := appendReturning(c.knownEngine(), query, c.cfg.ReturningColumn)
+
+	start := c.clock().Now()
 	out, err := c.execute(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Result{output: out}, nil
+	return &Result{output: out, returning: returning, engine: c.knownEngine(), duration: c.clock().Now().Sub(start)}, nil
 }
 
+// appendReturning appends a RETURNING clause for column col to single row INSERT statements
+// that don't have one already, so engines without generated fields (postgres) can report the
+// inserted ids. The clause goes before a trailing semicolon or comment. It reports whether
+// the query was rewritten.
+func appendReturning(engine, query, col string) (string, bool) {
+	if col == "" || classifyStatement(engine, query) != StatementInsert || !isSingleRowInsert(engine, query) {
+		return query, false
+	}
+
+	for _, w := range topLevelWords(engine, query) {
+		if strings.EqualFold(w, "RETURNING") {
+			return query, false
+		}
+	}
+
+	// everything after the last rune of the statement itself are comments, whitespace and
+	// semicolons, of which the comments are kept
+	sp, end := splitter{rs: []rune(query), engine: engine}, 0
+	for i := 0; i < len(sp.rs); i++ {
+		j, comment, err := sp.token(i)
+		if err != nil {
+			return query, false
+		}
+
+		if !comment && !unicode.IsSpace(sp.rs[i]) && sp.rs[i] != ';' {
+			end = j + 1
+		}
+
+		i = j
+	}
+
+	var trailing strings.Builder
+	for i := end; i < len(sp.rs); i++ {
+		j, _, _ := sp.token(i)
+		if sp.rs[i] != ';' {
+			trailing.WriteString(string(sp.rs[i : j+1]))
+		}
+
+		i = j
+	}
+
+	return strings.TrimSpace(string(sp.rs[:end]) + " RETURNING " + col + strings.TrimRightFunc(trailing.String(), unicode.IsSpace)), true
+}
+
+// isSingleRowInsert returns whether the INSERT statement inserts a single row from a VALUES
+// list (or DEFAULT VALUES), rather than several rows or the rows of a query.
+func isSingleRowInsert(engine, query string) bool {
+	sp, depth, values := splitter{rs: []rune(query), engine: engine}, 0, false
+	for i := 0; i < len(sp.rs); i++ {
+		end, _, err := sp.token(i)
+		if err != nil {
+			return false
+		}
+
+		if end > i {
+			i = end
+			continue
+		}
+
+		switch r := sp.rs[i]; {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0 && values:
+			return false // another row follows
+		case (unicode.IsLetter(r) || r == '_') && !isIdentRune(sp.at(i-1)):
+			j := i
+			for j < len(sp.rs) && isIdentRune(sp.rs[j]) {
+				j++
+			}
+
+			switch {
+			case depth > 0:
+			case values:
+				return true // the list of rows ended, e.g. at ON CONFLICT
+			case strings.EqualFold(string(sp.rs[i:j]), "VALUES"):
+				values = true
+			}
+
+			i = j - 1
+		}
+	}
+
+	return values
+}
+
+// QueryContext executes the query with a single call to the Data API, as with ExecContext
+// the sql package calls it directly (as a driver.QueryerContext) without preparing it.
 func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Rows, err error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, c.cfg.QueryTimeout)
+	defer cancel()
+
+	start := c.clock().Now()
 	out, err := c.execute(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Rows{output: out}, nil
+	if err = checkRecordWidth(out); err != nil {
+		return nil, err
+	}
+
+	return &Rows{output: out, cfg: c.cfg, duration: c.clock().Now().Sub(start)}, nil
 }
 
-func toParams(args []driver.NamedValue) (params []*rdsds.SqlParameter, err error) {
+// checkRecordWidth returns an error if the records of the output don't have a value for
+// each column of the result metadata, for example because it was not requested. The sql
+// package would otherwise fail on scanning with an error that hides the actual cause.
+func checkRecordWidth(out *rdsds.ExecuteStatementOutput) error {
+	for i, rec := range out.Records {
+		if len(rec) != len(out.ColumnMetadata) {
+			return fmt.Errorf("record %d has %d values but the result metadata describes %d columns, was it disabled with WithResultMetadata?", i, len(rec), len(out.ColumnMetadata))
+		}
+	}
+
+	return nil
+}
+
+// toParams converts the arguments of a statement into parameters for the Data API
+func (c *Conn) toParams(args []driver.NamedValue) (params []*rdsds.SqlParameter, err error) {
 	params = make([]*rdsds.SqlParameter, len(args))
 	for i, arg := range args {
+		if arg.Name == "" && c.cfg.OrdinalParams {
+			arg.Name = strconv.Itoa(arg.Ordinal)
+		}
+
 		if arg.Name == "" {
-			return nil, fmt.Errorf("support named SQL arguments are supported in query")
+			return nil, fmt.Errorf("argument %d has no name, only named arguments are supported (unless 'OrdinalParams' is configured): use sql.Named, e.g. db.Query(\"SELECT * FROM foo WHERE id = :id\", sql.Named(\"id\", 1))", arg.Ordinal)
 		}
 
-		var f rdsds.Field
+		var (
+			f    rdsds.Field
+			hint *string
+		)
+
 		switch t := arg.Value.(type) {
+		case nil: // e.g. a sql.NullString that is not valid, the sql package converts it to nil
+			f = rdsds.Field{IsNull: aws.Bool(true)}
 		case string:
+			if t == "" && c.cfg.EmptyStringAsNull {
+				f = rdsds.Field{IsNull: aws.Bool(true)}
+				break
+			}
+
+			if !utf8.ValidString(t) { // the JSON encoding of the Data API would mangle it
+				return nil, fmt.Errorf("argument '%s' is not a valid UTF-8 string, pass binary data as []byte instead", arg.Name)
+			}
+
 			f = rdsds.Field{StringValue: aws.String(t)}
 		case []byte:
 			f = rdsds.Field{BlobValue: t}
+		case Text:
+			if !utf8.Valid(t) {
+				return nil, fmt.Errorf("argument '%s' is not valid UTF-8 text, pass binary data as []byte instead", arg.Name)
+			}
+
+			f = rdsds.Field{StringValue: aws.String(string(t))}
 		case bool:
 			f = rdsds.Field{BooleanValue: &t}
 		case float64:
 			f = rdsds.Field{DoubleValue: &t}
 		case int64:
 			f = rdsds.Field{LongValue: &t}
+		case time.Time:
+			f, hint = rdsds.Field{StringValue: aws.String(t.UTC().Format(timestampFormat))}, aws.String(rdsds.TypeHintTimestamp)
+		case Date:
+			f, hint = rdsds.Field{StringValue: aws.String(time.Time(t).Format(dateFormat))}, aws.String(rdsds.TypeHintDate)
+		case TimeOfDay:
+			f, hint = rdsds.Field{StringValue: aws.String(time.Time(t).Format(timeFormat))}, aws.String(rdsds.TypeHintTime)
+		case UUID:
+			f = rdsds.Field{BlobValue: t[:]}
+		case Decimal:
+			f, hint = rdsds.Field{StringValue: aws.String(string(t))}, aws.String(rdsds.TypeHintDecimal)
 		default:
-			return nil, fmt.Errorf("supports string, []byte, bool, float64 or int64 for argument '%s', got: %T, ", arg.Name, arg.Value)
+			return nil, fmt.Errorf("%w %T for argument '%s': supports string, []byte, bool, float64, int64, time.Time, Date, TimeOfDay, UUID, Text or Decimal", ErrUnsupportedParamType, arg.Value, arg.Name)
+		}
+
+		if n := len(f.BlobValue) + len(aws.StringValue(f.StringValue)); n > c.cfg.maxParamBytes() {
+			return nil, fmt.Errorf("argument '%s' is %d bytes, which exceeds the max parameter size of %d bytes ('MaxParamBytes')", arg.Name, n, c.cfg.maxParamBytes())
 		}
 
 		params[i] = &rdsds.SqlParameter{
-			Name:  aws.String(arg.Name),
-			Value: &f,
+			Name:     aws.String(arg.Name),
+			Value:    &f,
+			TypeHint: hint,
 		}
 	}
 
 	return
 }
 
+// executeImplicitTx executes the statement in a transaction of its own, which is
+// committed if the statement succeeds and rolled back otherwise.
+func (c *Conn) executeImplicitTx(ctx context.Context, query string, args []driver.NamedValue) (out *rdsds.ExecuteStatementOutput, err error) {
+	if err = c.guardReadOnly(ctx, c.analyze(query).kind); err != nil {
+		return nil, err // before beginning, so a rejected statement doesn't cost a transaction
+	}
+
+	if _, err = c.BeginTx(ctx, driver.TxOptions{ReadOnly: c.cfg.ReadOnlyConnection}); err != nil {
+		return nil, fmt.Errorf("failed to begin implicit transaction: %w", err)
+	}
+
+	if out, err = c.execute(ctx, query, args); err != nil {
+		if rerr := c.Rollback(); rerr != nil {
+			return nil, fmt.Errorf("%v, and then failed to rollback implicit transaction: %w", err, rerr)
+		}
+
+		return nil, err
+	}
+
+	if err = c.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit implicit transaction: %w", err)
+	}
+
+	return
+}
+
 func (c *Conn) execute(ctx context.Context, query string, args []driver.NamedValue) (out *rdsds.ExecuteStatementOutput, err error) {
-	params, err := toParams(args)
+	if c.rdsDataService == nil {
+		return nil, fmt.Errorf("failed to execute statement: %w", ErrConnClosed)
+	}
+
+	if c.cfg.ImplicitTx && c.transactionID == "" && !driverStatementFromContext(ctx) {
+		return c.executeImplicitTx(ctx, query, args)
+	}
+
+	if c.transactionID != "" {
+		var cancel context.CancelFunc
+		ctx, cancel = c.withTxCancel(ctx)
+		defer cancel()
+	}
+
+	params, ok := paramsFromContext(ctx)
+	raw, isRaw := rawParamsFromContext(ctx)
+	switch {
+	case isRaw && len(args) > 0:
+		return nil, fmt.Errorf("statement has %d arguments as well as raw parameters from WithRawParams, provide only one of them", len(args))
+	case isRaw:
+		params = raw
+	case !ok || len(args) > 0:
+		if params, err = c.toParams(args); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.cfg.StatementInterceptor != nil {
+		if query, params, err = c.cfg.StatementInterceptor(ctx, query, params); err != nil {
+			return nil, fmt.Errorf("statement interceptor failed: %w", err)
+		}
+	}
+
+	a := c.analyze(query)
+	if err = c.guardReadOnly(ctx, a.kind); err != nil {
+		return nil, err
+	}
+
+	if err = c.guardWrite(ctx, query, a); err != nil {
+		return nil, err
+	}
+
+	meta, ok := resultMetadataFromContext(ctx)
+	if !ok {
+		meta = a.metadata
+	}
+
+	resourceARN, secretARN, err := c.resourceFor(ctx, a.kind)
 	if err != nil {
 		return nil, err
 	}
 
 	in := &rdsds.ExecuteStatementInput{
-		// ResultSetOptions @TODO allow the user to configure this
-		// Schema @TODO allow the user to pass a schema this
 		// ContinueAfterTimeout:  aws.Bool(false), @TODO allow this to be configurable
-		IncludeResultMetadata: aws.Bool(true), //must be set to true for row iteration
+		IncludeResultMetadata: aws.Bool(meta), //must be set to true for row iteration
 		Parameters:            params,
 		Database:              aws.String(c.databaseName),
-		ResourceArn:           aws.String(c.resourceARN),
-		SecretArn:             aws.String(c.secretARN),
+		ResourceArn:           aws.String(resourceARN),
+		SecretArn:             aws.String(secretARN),
 		Sql:                   aws.String(query),
 	}
 
@@ -225,18 +636,137 @@ func (c *Conn) execute(ctx context.Context, query string, args []driver.NamedVal
 		in.SetTransactionId(c.transactionID)
 	}
 
-	if out, err = c.rdsDataService.ExecuteStatementWithContext(ctx, in); err != nil {
-		return nil, fmt.Errorf("failed to execute statement: %w", err)
+	if schema := c.schema(ctx); schema != "" {
+		in.SetSchema(schema)
+	}
+
+	if opts, ok := resultSetOptionsFromContext(ctx); ok {
+		in.SetResultSetOptions(opts)
+	} else if c.cfg.DecimalReturnType != "" {
+		in.SetResultSetOptions(&rdsds.ResultSetOptions{DecimalReturnType: aws.String(c.cfg.DecimalReturnType)})
+	}
+
+	if c.cfg.Metrics != nil {
+		c.cfg.Metrics.IncExec()
+	}
+
+	start := c.clock().Now()
+	call := func() error {
+		return c.limit(ctx, func() (err error) {
+			out, err = c.rdsDataService.ExecuteStatementWithContext(ctx, in)
+			return
+		})
+	}
+
+	err = c.stmtRetryer(ctx, a.kind).do(ctx, call)
+
+	// postgres aborts the transaction on the first error, so only retry outside of one
+	if c.cfg.AutoCast && c.transactionID == "" {
+		if cast, ok := autoCast(query, err); ok {
+			in.SetSql(cast)
+			err = call()
+		} else if cast, ps, ok := autoCastLimit(query, in.Parameters, err); ok {
+			in.SetSql(cast).SetParameters(ps)
+			err = call()
+		}
+	}
+
+	c.checkSlow(ctx, query, params, start)
+	if c.observe(OpExecute, start, err); err != nil {
+		return nil, fmt.Errorf("failed to execute statement%s: %w", c.paramsError(ctx, params), c.checkErr(err))
+	}
+
+	// the Data API doesn't keep session state, so remember the switch for later statements
+	if a.isUse {
+		c.SetDatabase(a.use)
 	}
 
 	return
 }
 
+// useStmt matches a statement that switches the default database, e.g: 'USE foo'
+var useStmt = regexp.MustCompile("(?i)^\\s*USE\\s+(`[^`]+`|\"[^\"]+\"|[^\\s;]+)\\s*;?\\s*$")
+
+// SetDatabase changes the database for all following statements on this connection. It
+// is also called when a 'USE <database>' statement is executed successfully, as the Data
+// API itself doesn't remember it in between calls.
+func (c *Conn) SetDatabase(name string) { c.databaseName = name }
+
+// Config returns a copy of the effective configuration of the connection for diagnostics:
+// the region it resolved to, the current database and the secret ARN it resolved to,
+// masked by maskSecretARN. The password of the ProxyURL, if any, is masked as well.
+func (c *Conn) Config() Config {
+	cfg := c.cfg
+	cfg.Database, cfg.ResourceARN = c.databaseName, c.resourceARN
+	cfg.SecretARN = maskSecretARN(c.secretARN)
+	cfg.ProxyURL = maskURLPassword(cfg.ProxyURL)
+	return cfg
+}
+
+// includeMetadata returns whether the result metadata is requested for a statement of the
+// given kind. Writes only return records (and need metadata) when they have a RETURNING clause.
+// It can be overwritten per statement with WithResultMetadata.
+func includeMetadata(kind StatementKind, query string) bool {
+	switch kind {
+	case StatementInsert, StatementUpdate, StatementDelete, StatementDDL:
+		return strings.Contains(strings.ToUpper(query), "RETURNING")
+	default:
+		return true
+	}
+}
+
+// resourceFor returns the ARNs of the resource and secret that a statement of the given kind
+// is send with: those from WithResource, else the reader if the context asks for it, the
+// statement only reads and it is not part of a transaction. A transaction can't span
+// resources, so within one the context must provide the resource it began with.
+func (c *Conn) resourceFor(ctx context.Context, kind StatementKind) (resourceARN, secretARN string, err error) {
+	r, ok := resourceFromContext(ctx)
+	if c.transactionID != "" && r != c.txResource {
+		return "", "", fmt.Errorf("transaction '%s' began on resource '%s', it can't be used for a statement on resource '%s'",
+			c.transactionID, c.orDefault(c.txResource).resourceARN, c.orDefault(r).resourceARN)
+	}
+
+	if ok {
+		return r.resourceARN, r.secretARN, nil
+	}
+
+	if c.cfg.ReaderResourceARN != "" && c.transactionID == "" && kind == StatementSelect && readerFromContext(ctx) {
+		return c.cfg.ReaderResourceARN, c.secretARN, nil
+	}
+
+	return c.resourceARN, c.secretARN, nil
+}
+
+// txARNs returns the ARNs of the resource and secret of the current transaction
+func (c *Conn) txARNs() (resourceARN, secretARN string) {
+	r := c.orDefault(c.txResource)
+	return r.resourceARN, r.secretARN
+}
+
+// orDefault returns r, or the connection's own resource if r is the zero value
+func (c *Conn) orDefault(r resource) resource {
+	if r == (resource{}) {
+		return resource{resourceARN: c.resourceARN, secretARN: c.secretARN}
+	}
+
+	return r
+}
+
+// schema returns the schema for statements executed with ctx, the schema from the context
+// takes precedence over the one configured for the connection.
+func (c *Conn) schema(ctx context.Context) string {
+	if schema, ok := schemaFromContext(ctx); ok {
+		return schema
+	}
+
+	return c.cfg.Schema
+}
+
 // Begin starts and returns a new transaction.
 //
 // Deprecated: Drivers should implement ConnBeginTx instead (or additionally).
 func (c *Conn) Begin() (driver.Tx, error) {
-	return c.BeginTx(context.Background(), sql.TxOptions{})
+	return c.BeginTx(context.Background(), driver.TxOptions{})
 }
 
 // Prepare returns a prepared statement, bound to this connection.
@@ -246,11 +776,19 @@ func (c *Conn) Prepare(query string) (driver.Stmt, error) {
 
 // Rows is an iterator over an executed query's results.
 type Rows struct {
-	output *rdsds.ExecuteStatementOutput
-	closed bool
-	pos    int
+	output   *rdsds.ExecuteStatementOutput
+	closed   bool
+	pos      int
+	cfg      Config
+	duration time.Duration
 }
 
+// Warnings returns any warnings the engine reported for the query.
+func (r *Rows) Warnings() []string { return warnings(r.output) }
+
+// Duration returns how long executing the query took, see Result.Duration.
+func (r *Rows) Duration() time.Duration { return r.duration }
+
 // Close closes the rows iterator.
 func (r *Rows) Close() error { r.closed = true; return nil }
 
@@ -262,11 +800,42 @@ func (r *Rows) Columns() (cols []string) {
 	cols = make([]string, len(r.output.ColumnMetadata))
 	for i, c := range r.output.ColumnMetadata {
 		cols[i] = aws.StringValue(c.Name)
+		if r.cfg.ColumnNames != ColumnNamesName && aws.StringValue(c.Label) != "" {
+			cols[i] = aws.StringValue(c.Label)
+		}
+	}
+
+	if r.cfg.DedupColumns {
+		dedupColumns(cols)
 	}
 
 	return
 }
 
+// dedupColumns renames columns with a name that was already used by an earlier column by
+// suffixing it with '_2', '_3', etc. Such that each column can be identified by name.
+func dedupColumns(cols []string) {
+	seen := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		seen[c] = true
+	}
+
+	used := make(map[string]bool, len(cols))
+	for i, c := range cols {
+		if !used[c] {
+			used[c] = true
+			continue
+		}
+
+		name := c
+		for n := 2; used[name] || (seen[name] && name != c); n++ {
+			name = fmt.Sprintf("%s_%d", c, n)
+		}
+
+		cols[i], used[name] = name, true
+	}
+}
+
 // Next is called to populate the next row of data into
 // the provided slice. The provided slice will be the same
 // size as the Columns() are wide.
@@ -281,7 +850,7 @@ func (r *Rows) Next(dest []driver.Value) (err error) {
 		return fmt.Errorf("rows already closed") //@TODO test
 	}
 
-	if r.pos == len(r.output.Records) {
+	if r.pos >= len(r.output.Records) { // also for results with columns but zero records
 		return io.EOF
 	}
 
@@ -290,7 +859,7 @@ func (r *Rows) Next(dest []driver.Value) (err error) {
 	r.pos++
 
 	for i, field := range row {
-		dest[i], err = decodeField(field)
+		dest[i], err = r.decodeColumn(i, field)
 		if err != nil {
 			return fmt.Errorf("failed to decode field value: %w", err) //@TODO test
 		}
@@ -300,22 +869,127 @@ func (r *Rows) Next(dest []driver.Value) (err error) {
 }
 
 // Result is the result of a query execution.
-type Result struct{ output *rdsds.ExecuteStatementOutput }
+type Result struct {
+	output    *rdsds.ExecuteStatementOutput
+	returning bool   // whether generated ids are returned as records by an appended RETURNING clause
+	engine    string // the engine of the cluster if it is known, see Conn.knownEngine
+	duration  time.Duration
+}
+
+// generated returns the fields that hold the ids generated by the statement
+func (r *Result) generated() (fields []*rdsds.Field) {
+	if !r.returning {
+		return r.output.GeneratedFields
+	}
+
+	for _, rec := range r.output.Records {
+		if len(rec) > 0 {
+			fields = append(fields, rec[0])
+		}
+	}
+
+	return
+}
 
 // LastInsertId returns the database's auto-generated ID
 // after, for example, an INSERT into a table with primary
 // key.
 func (r *Result) LastInsertId() (id int64, err error) {
-	if len(r.output.GeneratedFields) != 1 {
-		return -1, fmt.Errorf("LastInsertId not supported by postgres engine AND demands the exec to return exactly one generated field, got: %d", len(r.output.GeneratedFields))
+	return lastInsertID(r.generated(), r.engine)
+}
+
+// lastInsertID returns the id of the single generated field, the error explains why there
+// isn't one in terms of the engine, if it is known.
+func lastInsertID(gfields []*rdsds.Field, engine string) (int64, error) {
+	switch {
+	case len(gfields) > 1:
+		return -1, fmt.Errorf("statement generated %d fields, a multi-row insert has no single last insert id: use GeneratedIDs instead", len(gfields))
+	case len(gfields) == 1:
+		return generatedID(gfields[0])
+	case engine == EnginePostgres:
+		return -1, fmt.Errorf("postgres doesn't report generated fields: use a RETURNING clause, or configure 'ReturningColumn' for statements that are not prepared")
+	case engine == EngineMySQL:
+		return -1, fmt.Errorf("statement generated no fields, e.g. because the table has no AUTO_INCREMENT column or no row was inserted")
+	default:
+		return -1, fmt.Errorf("statement generated no fields: on MySQL the table needs an AUTO_INCREMENT column, postgres doesn't report generated fields at all (use a RETURNING clause, or configure 'ReturningColumn' for statements that are not prepared)")
+	}
+}
+
+// generatedID returns the integer id in a generated field. It is usually a long value but
+// some engines report large serials as a string value, which is then parsed.
+func generatedID(f *rdsds.Field) (int64, error) {
+	if f.LongValue != nil {
+		return aws.Int64Value(f.LongValue), nil
 	}
 
-	f := r.output.GeneratedFields[0]
-	if f.LongValue == nil {
-		return -1, fmt.Errorf("generated field is not a non-nil long value")
+	if f.StringValue == nil {
+		return -1, fmt.Errorf("generated field is neither a long nor a string value")
 	}
 
-	return aws.Int64Value(f.LongValue), nil
+	id, err := strconv.ParseInt(aws.StringValue(f.StringValue), 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf("generated field is a string value that is not an integer: %w", err)
+	}
+
+	return id, nil
+}
+
+// GeneratedIDs returns all auto-generated IDs, for example for each row of a
+// multi-row INSERT. It returns an error if any generated field isn't an integer.
+func (r *Result) GeneratedIDs() (ids []int64, err error) {
+	gfields := r.generated()
+	ids = make([]int64, len(gfields))
+	for i, f := range gfields {
+		if ids[i], err = generatedID(f); err != nil {
+			return nil, fmt.Errorf("generated field %d: %w", i, err)
+		}
+	}
+
+	return
+}
+
+// GeneratedStringID returns the auto-generated ID for tables with a non-integer primary
+// key, for example an UUID, that the Data API reports as a string value.
+func (r *Result) GeneratedStringID() (id string, err error) {
+	gfields := r.generated()
+	if len(gfields) != 1 {
+		return "", fmt.Errorf("demands the exec to return exactly one generated field, got: %d", len(gfields))
+	}
+
+	if gfields[0].StringValue == nil {
+		return "", fmt.Errorf("generated field is not a non-nil string value")
+	}
+
+	return aws.StringValue(gfields[0].StringValue), nil
+}
+
+// GeneratedBytesID returns the auto-generated ID for tables with a binary primary key
+// that the Data API reports as a blob value.
+func (r *Result) GeneratedBytesID() (id []byte, err error) {
+	gfields := r.generated()
+	if len(gfields) != 1 {
+		return nil, fmt.Errorf("demands the exec to return exactly one generated field, got: %d", len(gfields))
+	}
+
+	if gfields[0].BlobValue == nil {
+		return nil, fmt.Errorf("generated field is not a non-nil blob value")
+	}
+
+	return gfields[0].BlobValue, nil
+}
+
+// Warnings returns any warnings the engine reported for the statement.
+func (r *Result) Warnings() []string { return warnings(r.output) }
+
+// Duration returns how long executing the statement took, as measured by the driver. It
+// includes any retries (and, with ImplicitTx, beginning and committing the transaction). The
+// Data API doesn't report how long the database itself took.
+func (r *Result) Duration() time.Duration { return r.duration }
+
+// NumberOfRecordsUpdated returns the nr of updated records exactly as reported by
+// the Data API.
+func (r *Result) NumberOfRecordsUpdated() int64 {
+	return aws.Int64Value(r.output.NumberOfRecordsUpdated)
 }
 
 // RowsAffected returns the number of rows affected by the
@@ -324,6 +998,13 @@ func (r *Result) RowsAffected() (n int64, err error) {
 	return aws.Int64Value(r.output.NumberOfRecordsUpdated), nil
 }
 
+// warnings returns the warnings in the output of a statement. The current version of
+// the Data API (and SDK) doesn't report any warnings, so this always returns an empty
+// slice until it does.
+func warnings(out *rdsds.ExecuteStatementOutput) []string {
+	return []string{}
+}
+
 func decodeField(f *rdsds.Field) (v interface{}, err error) {
 	switch {
 	case f.BlobValue != nil:
@@ -341,49 +1022,244 @@ func decodeField(f *rdsds.Field) (v interface{}, err error) {
 	default:
 		return nil, fmt.Errorf("field has no defined value")
 	}
-
-	return
 }
 
 type Stmt struct {
 	query   string
 	conn    *Conn
+	schema  string    // schema of the batch, as determined when the statement was prepared
+	res     *resource // resource of the batch from the context it was prepared with, if any
 	closed  bool
 	sets    [][]*rdsds.SqlParameter
 	updates []*rdsds.UpdateResult
+
+	executed   int  // nr of sets that were executed and trimmed from sets, the index of sets[0]
+	reset      int  // nr of times the sets were discarded by Reset, see StmtResult
+	rolledBack bool // whether the last batch transaction was rolled back, see StmtResult
 }
 
+// Close executes the accumulated parameter sets as a batch. The sql package provides no
+// context for this, so the context of the current transaction is used while it is valid.
+// Either way the batch is bounded by the ExecTimeout.
 func (s *Stmt) Close() (err error) {
+	ctx, cancel := s.conn.txContext(s.conn.cfg.ExecTimeout)
+	defer cancel()
+
+	return s.CloseContext(ctx)
+}
+
+// CloseContext executes the accumulated parameter sets as a batch with the provided
+// context. If the context is done before the batch completes the statement is closed
+// anyway, such that it can't be re-used with only part of its sets executed. Otherwise a
+// failed batch keeps the sets that were not executed, and calling CloseContext again (e.g.
+// through sql.Conn.Raw) retries them. This is best-effort: the sql package drops the error
+// of closing a statement of a *sql.DB, so its callers usually can't tell a retry is needed.
+func (s *Stmt) CloseContext(ctx context.Context) (err error) {
 	if s.closed {
 		return fmt.Errorf("already closed") //@TODO test
 	}
 
-	// @TODO document limitation of this
-	ctx := context.Background()
+	if len(s.sets) == 0 { // nothing was executed, e.g. the statement was only used to query
+		s.closed = true
+		return nil
+	}
+
+	if s.res != nil {
+		ctx = WithResource(ctx, s.res.resourceARN, s.res.secretARN)
+	}
+
+	query, sets, err := s.intercept(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx := s.conn.cfg.BatchTx && s.conn.transactionID == ""
+	if tx {
+		if _, err = s.conn.BeginTx(ctx, driver.TxOptions{}); err != nil {
+			return fmt.Errorf("failed to begin batch transaction: %w", err)
+		}
+	}
+
+	updates, done, err := s.executeChunks(ctx, query, sets)
+	if tx && err != nil {
+		if rerr := s.conn.Rollback(); rerr != nil {
+			err = fmt.Errorf("%v, and then failed to rollback batch transaction: %w", err, rerr)
+		}
+
+		updates, done, s.rolledBack = nil, 0, true
+	} else if tx {
+		if err = s.conn.Commit(); err != nil {
+			err = fmt.Errorf("failed to commit batch transaction: %w", err)
+			updates, done = nil, 0
+		}
+	}
+
+	s.updates = append(s.updates, updates...)
+	if err != nil {
+		s.closed, s.sets, s.executed = ctx.Err() != nil, s.sets[done:], s.executed+done
+		return err
+	}
+
+	s.closed, s.rolledBack = true, false
+	return nil
+}
+
+// executeChunks executes the sets in batches of the configured size. It returns the results
+// and nr of the sets that were executed before any error.
+func (s *Stmt) executeChunks(ctx context.Context, query string, sets [][]*rdsds.SqlParameter) (updates []*rdsds.UpdateResult, done int, err error) {
+	chunks := chunkSets(sets, s.conn.cfg.batchSize(), maxRequestBytes-len(query))
+	for i, chunk := range chunks {
+		var us []*rdsds.UpdateResult
+		if us, err = s.executeBatch(ctx, query, chunk); err != nil {
+			if len(chunks) > 1 {
+				err = fmt.Errorf("failed to execute batch %d of %d, after %d parameter sets were executed: %w", i+1, len(chunks), done, err)
+			}
+
+			return updates, done, err
+		}
+
+		updates, done = append(updates, us...), done+len(chunk)
+	}
+
+	return updates, done, nil
+}
+
+// executeBatch executes the query with the parameter sets in a single call to the Data API
+func (s *Stmt) executeBatch(ctx context.Context, query string, sets [][]*rdsds.SqlParameter) ([]*rdsds.UpdateResult, error) {
+	resourceARN, secretARN, err := s.conn.resourceFor(ctx, StatementOther)
+	if err != nil {
+		return nil, err
+	}
 
 	in := &rdsds.BatchExecuteStatementInput{
 		Database:      aws.String(s.conn.databaseName),
-		ParameterSets: s.sets,
-		ResourceArn:   aws.String(s.conn.resourceARN),
-		// Schema @TODO allow the user to pass a schema this
-		SecretArn: aws.String(s.conn.secretARN),
-		Sql:       aws.String(s.query),
+		ParameterSets: sets,
+		ResourceArn:   aws.String(resourceARN),
+		SecretArn:     aws.String(secretARN),
+		Sql:           aws.String(query),
+	}
+
+	if s.schema != "" {
+		in.SetSchema(s.schema)
 	}
 
 	if s.conn.transactionID != "" {
+		var cancel context.CancelFunc
+		ctx, cancel = s.conn.withTxCancel(ctx)
+		defer cancel()
 		in.SetTransactionId(s.conn.transactionID)
 	}
 
+	if s.conn.cfg.Metrics != nil {
+		s.conn.cfg.Metrics.IncBatch()
+	}
+
 	var out *rdsds.BatchExecuteStatementOutput
-	if out, err = s.conn.rdsDataService.BatchExecuteStatementWithContext(ctx, in); err != nil {
-		return fmt.Errorf("failed to execute batch statement: %w", err) //@TODO test
+	start := s.conn.clock().Now()
+	err = s.conn.stmtRetryer(ctx, s.conn.analyze(query).kind).do(ctx, func() error {
+		return s.conn.limit(ctx, func() (err error) {
+			out, err = s.conn.rdsDataService.BatchExecuteStatementWithContext(ctx, in)
+			return
+		})
+	})
+	if s.conn.cfg.SlowQueryThreshold > 0 {
+		var params []*rdsds.SqlParameter
+		for _, set := range sets {
+			params = append(params, set...)
+		}
+
+		s.conn.checkSlow(ctx, query, params, start)
 	}
 
-	s.updates = out.UpdateResults
-	s.closed = true
+	if s.conn.observe(OpBatch, start, err); err != nil {
+		return nil, fmt.Errorf("failed to execute batch statement: %w", s.conn.checkErr(err)) //@TODO test
+	}
+
+	return out.UpdateResults, nil
+}
+
+// chunkSets splits the parameter sets into chunks of at most size sets, that are also at
+// most maxBytes large in total. A batch without any sets is a single empty chunk.
+func chunkSets(sets [][]*rdsds.SqlParameter, size, maxBytes int) (chunks [][][]*rdsds.SqlParameter) {
+	start, n := 0, 0
+	for i, set := range sets {
+		b := setBytes(set)
+		if i > start && (i-start >= size || n+b > maxBytes) {
+			chunks, start, n = append(chunks, sets[start:i]), i, 0
+		}
+
+		n += b
+	}
+
+	return append(chunks, sets[start:])
+}
+
+// setBytes approximates the nr of bytes a parameter set adds to a request
+func setBytes(set []*rdsds.SqlParameter) (n int) {
+	for _, p := range set {
+		if n += len(aws.StringValue(p.Name)) + 16; p.Value != nil {
+			n += len(p.Value.BlobValue) + len(aws.StringValue(p.Value.StringValue))
+		}
+	}
+
+	return
+}
+
+// intercept runs the connection's statement interceptor for each parameter set of the
+// batch. Since a batch has a single sql statement, the interceptor must return the same
+// sql for each set.
+func (s *Stmt) intercept(ctx context.Context) (query string, sets [][]*rdsds.SqlParameter, err error) {
+	icpt := s.conn.cfg.StatementInterceptor
+	if icpt == nil {
+		return s.query, s.sets, nil
+	}
+
+	if len(s.sets) == 0 {
+		query, _, err = icpt(ctx, s.query, nil)
+		if err != nil {
+			return "", nil, fmt.Errorf("statement interceptor failed: %w", err)
+		}
+
+		return query, nil, nil
+	}
+
+	sets = make([][]*rdsds.SqlParameter, len(s.sets))
+	for i, set := range s.sets {
+		var q string
+		if q, sets[i], err = icpt(ctx, s.query, set); err != nil {
+			return "", nil, fmt.Errorf("statement interceptor failed for parameter set %d: %w", i, err)
+		}
+
+		if i > 0 && q != query {
+			return "", nil, fmt.Errorf("statement interceptor returned different sql for parameter set %d of the batch", i)
+		}
+
+		query = q
+	}
+
+	return
+}
+
+// PendingSets returns the nr of parameter sets that have been accumulated by calls to
+// Exec and will be executed as a batch when the statement is closed.
+func (s *Stmt) PendingSets() int { return len(s.sets) }
+
+// Reset discards any parameter sets that have been accumulated so far, without executing
+// them. It returns an error if the statement was already closed.
+func (s *Stmt) Reset() (err error) {
+	if s.closed {
+		return fmt.Errorf("already closed")
+	}
+
+	s.sets, s.updates, s.executed, s.rolledBack = nil, nil, 0, false
+	s.reset++
 	return nil
 }
 
+// UpdateResults returns the update results exactly as reported by the Data API for the
+// batch that was executed when the statement was closed, it is nil before that.
+func (s *Stmt) UpdateResults() []*rdsds.UpdateResult { return s.updates }
+
 func (s *Stmt) NumInput() int {
 	return -1 // AWS Doesn't expose the query parsing so we cannot help the user here.
 }
@@ -393,13 +1269,18 @@ func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (_ dri
 		return nil, fmt.Errorf("already closed") //@TODO test
 	}
 
-	params, err := toParams(args)
+	params, err := s.conn.toParams(args)
 	if err != nil {
 		return nil, err
 	}
 
+	// the sets of a batch are for the same sql, so they must all have parameters or none
+	if len(s.sets) > 0 && (len(s.sets[0]) == 0) != (len(params) == 0) {
+		return nil, fmt.Errorf("parameter set %d has %d parameters, but the first set of the batch has %d", len(s.sets), len(params), len(s.sets[0]))
+	}
+
 	s.sets = append(s.sets, params)
-	return &StmtResult{stmt: s, i: len(s.sets) - 1}, nil
+	return &StmtResult{stmt: s, i: s.executed + len(s.sets) - 1, reset: s.reset}, nil
 }
 
 func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (_ driver.Rows, err error) {
@@ -407,7 +1288,8 @@ func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (_ dr
 		return nil, fmt.Errorf("already closed") //@TODO test
 	}
 
-	return nil, fmt.Errorf("this driver cannot return any usefull results for prepared query statements.")
+	// reads don't benefit from batching so queries are executed immediately
+	return s.conn.QueryContext(ctx, s.query, args)
 }
 
 func (s *Stmt) Exec(args []driver.Value) (_ driver.Result, err error) {
@@ -418,23 +1300,27 @@ func (s *Stmt) Query(args []driver.Value) (_ driver.Rows, err error) {
 	panic("not implemented, use QueryContext")
 }
 
+// StmtResult is the result of adding a parameter set to the batch of a prepared statement,
+// it refers to the set by its index in the whole batch.
 type StmtResult struct {
-	stmt *Stmt
-	i    int
+	stmt  *Stmt
+	i     int
+	reset int // the Stmt's reset count when the set was added
 }
 
+// LastInsertId returns the id generated for the parameter set, it returns an error if the
+// set was not executed (yet).
 func (r *StmtResult) LastInsertId() (id int64, err error) {
-	gfields := r.stmt.updates[r.i].GeneratedFields
-	if len(gfields) != 1 {
-		return -1, fmt.Errorf("LastInsertId not supported by postgres engine AND demands the exec to return exactly one generated field, got: %d", len(gfields))
-	}
-
-	f := gfields[0]
-	if f.LongValue == nil {
-		return -1, fmt.Errorf("generated field is not a non-nil long value")
+	switch s := r.stmt; {
+	case r.reset != s.reset:
+		return -1, fmt.Errorf("parameter set %d was discarded by Reset", r.i)
+	case r.i >= len(s.updates) && s.rolledBack:
+		return -1, fmt.Errorf("parameter set %d was not executed, the batch was rolled back", r.i)
+	case r.i >= len(s.updates):
+		return -1, fmt.Errorf("parameter set %d has not been executed, the batch is executed when the statement is closed", r.i)
 	}
 
-	return aws.Int64Value(f.LongValue), nil
+	return lastInsertID(r.stmt.updates[r.i].GeneratedFields, r.stmt.conn.knownEngine())
 }
 
 func (r *StmtResult) RowsAffected() (n int64, err error) {
@@ -1,6 +1,7 @@
 package rdsdataapi_test
 
 import (
+	"bytes"
 	"database/sql"
 	"net/url"
 	"os"
@@ -338,3 +339,36 @@ func TestDriverPrepare(t *testing.T) {
 		t.Fatalf("failed to drop table: %v", err)
 	}
 }
+
+func TestDriverPostgresBytea(t *testing.T) {
+	cfg := envCfgOrSkip(t)
+	if os.Getenv("DATA_API_ENGINE") != "postgres" {
+		t.Skipf("please set DATA_API_ENGINE=postgres to test against a postgres cluster")
+	}
+
+	cfg.Add("Database", "postgres")
+	db, err := sql.Open("rds-data-api", cfg.Encode())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+
+	if _, err = db.Exec("CREATE TABLE IF NOT EXISTS blobs (id int PRIMARY KEY, data bytea)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	defer db.Exec("DROP TABLE IF EXISTS blobs")
+
+	data := []byte{0x00, 0x01, 0x5c, 0x78, 0xff}
+	if _, err = db.Exec("INSERT INTO blobs VALUES (1, :data)", sql.Named("data", data)); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	var blob []byte
+	if err = db.QueryRow("SELECT data FROM blobs WHERE id = 1").Scan(&blob); err != nil {
+		t.Fatalf("failed to select: %v", err)
+	}
+
+	if !bytes.Equal(blob, data) {
+		t.Fatalf("expected the binary data to round-trip, got: %x", blob)
+	}
+}
@@ -0,0 +1,117 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	// EngineMySQL is the engine of Aurora MySQL clusters
+	EngineMySQL = "mysql"
+
+	// EnginePostgres is the engine of Aurora PostgreSQL clusters
+	EnginePostgres = "postgres"
+)
+
+// engine returns the database engine of the cluster: the configured one or else the one
+// detected by the first call. The result is cached on the connector as all its connections
+// access the same cluster. Detections are serialized by detectMu, engineMu is not held while
+// detecting as executing the probe reads the known engine.
+func (c *Conn) engine(ctx context.Context) (string, error) {
+	if c.cfg.Engine != "" {
+		return c.cfg.Engine, nil
+	}
+
+	c.connector.detectMu.Lock()
+	defer c.connector.detectMu.Unlock()
+	if engine := c.knownEngine(); engine != "" {
+		return engine, nil
+	}
+
+	// version() exists on both engines, as a driver statement this doesn't recurse into an
+	// implicit transaction
+	out, err := c.execute(withDriverStatement(ctx), "SELECT version()", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect database engine: %w", err)
+	}
+
+	if len(out.Records) != 1 || len(out.Records[0]) != 1 {
+		return "", fmt.Errorf("failed to detect database engine: unexpected version result")
+	}
+
+	engine := EngineMySQL
+	if strings.Contains(strings.ToLower(aws.StringValue(out.Records[0][0].StringValue)), "postgres") {
+		engine = EnginePostgres
+	}
+
+	c.connector.engineMu.Lock()
+	c.connector.engine = engine
+	c.connector.engineMu.Unlock()
+	return engine, nil
+}
+
+// knownEngine returns the configured engine or the one that was detected earlier, without
+// detecting it. It returns an empty string if the engine is not known.
+func (c *Conn) knownEngine() string {
+	if c.cfg.Engine != "" || c.connector == nil {
+		return c.cfg.Engine
+	}
+
+	c.connector.engineMu.Lock()
+	defer c.connector.engineMu.Unlock()
+	return c.connector.engine
+}
+
+// engineOf returns the engine of the cluster that db executes statements on, detecting it if
+// needed. It returns an empty string if the driver's connection can't be reached through db,
+// e.g. for a *sql.Tx, or if db doesn't use this driver.
+func engineOf(ctx context.Context, db Execer) (engine string, err error) {
+	var sc *sql.Conn
+	switch db := db.(type) {
+	case *sql.Conn:
+		sc = db
+	case *sql.DB:
+		if sc, err = db.Conn(ctx); err != nil {
+			return "", fmt.Errorf("failed to get connection: %w", err)
+		}
+
+		defer sc.Close()
+	default:
+		return "", nil
+	}
+
+	err = sc.Raw(func(dc interface{}) (err error) {
+		if c, ok := dc.(*Conn); ok {
+			engine, err = c.engine(ctx)
+		}
+
+		return err
+	})
+
+	return engine, err
+}
+
+// setStatementTimeout applies the configured StatementTimeout to the session of the current
+// transaction with the SET statement of the engine.
+func (c *Conn) setStatementTimeout(ctx context.Context) error {
+	engine, err := c.engine(ctx)
+	if err != nil {
+		return err
+	}
+
+	ms := c.cfg.StatementTimeout.Milliseconds()
+	set := fmt.Sprintf("SET SESSION MAX_EXECUTION_TIME = %d", ms)
+	if engine == EnginePostgres {
+		set = fmt.Sprintf("SET LOCAL statement_timeout = %d", ms)
+	}
+
+	if _, err = c.execute(withDriverStatement(ctx), set, nil); err != nil {
+		return fmt.Errorf("failed to set statement timeout: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// versionAPI returns a mock that reports the given version for 'SELECT version()'
+func versionAPI(version string) *mockAPI {
+	return &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if aws.StringValue(in.Sql) != "SELECT version()" {
+			return &rdsds.ExecuteStatementOutput{}, nil
+		}
+
+		return &rdsds.ExecuteStatementOutput{Records: [][]*rdsds.Field{{{StringValue: aws.String(version)}}}}, nil
+	}}
+}
+
+func TestStatementTimeout(t *testing.T) {
+	for i, c := range []struct {
+		version string
+		engine  string
+		expSet  string
+	}{
+		{"5.7.mysql_aurora.2.07.2", "", "SET SESSION MAX_EXECUTION_TIME = 1500"},
+		{"PostgreSQL 10.14 on x86_64-pc-linux-gnu", "", "SET LOCAL statement_timeout = 1500"},
+		{"", EnginePostgres, "SET LOCAL statement_timeout = 1500"},
+	} {
+		m := versionAPI(c.version)
+		cfg := testCfg
+		cfg.StatementTimeout = 1500 * time.Millisecond
+		cfg.Engine = c.engine
+
+		cn := newConnector(cfg, m)
+		for j := 0; j < 2; j++ {
+			conn, _ := cn.Connect(context.Background())
+			if _, err := conn.(*Conn).BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+				t.Fatalf("%d: failed to begin: %v", i, err)
+			}
+		}
+
+		var sets []*rdsds.ExecuteStatementInput
+		for _, in := range m.executes {
+			if aws.StringValue(in.Sql) != "SELECT version()" {
+				sets = append(sets, in)
+			}
+		}
+
+		detections := len(m.executes) - len(sets)
+		if (c.engine == "" && detections != 1) || (c.engine != "" && detections != 0) {
+			t.Fatalf("%d: expected the engine to be detected at most once, got: %d executions", i, len(m.executes))
+		}
+
+		if len(sets) != 2 || aws.StringValue(sets[0].Sql) != c.expSet || aws.StringValue(sets[0].TransactionId) != "tx1" {
+			t.Fatalf("%d: expected '%s' in each transaction, got: %v", i, c.expSet, sets)
+		}
+	}
+}
+
+func TestEngineDetectionExecute(t *testing.T) {
+	n, rec := 0, &recordingMetrics{}
+	m := versionAPI("PostgreSQL 10.14 on x86_64-pc-linux-gnu")
+	version := m.ExecuteStatement
+	m.ExecuteStatement = func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if n++; n == 1 {
+			return nil, awserr.New("ThrottlingException", "slow down", nil)
+		}
+
+		return version(in)
+	}
+
+	conn := mockConn(t, m)
+	conn.cfg.ImplicitTx, conn.cfg.Metrics = true, rec
+	conn.retryer = instantRetryer(1)
+	conn.retryer.metrics = rec
+
+	engine, err := conn.engine(context.Background())
+	if err != nil || engine != EnginePostgres {
+		t.Fatalf("expected postgres to be detected after a retry, got: %q (%v)", engine, err)
+	}
+
+	if len(m.begins) != 0 || len(m.executes) != 2 {
+		t.Fatalf("expected the probe to be retried outside of an implicit transaction, got: %d begins", len(m.begins))
+	}
+
+	if exp := []string{"exec", "retry", OpExecute}; !reflect.DeepEqual(rec.calls, exp) {
+		t.Fatalf("expected metrics calls %v, got: %v", exp, rec.calls)
+	}
+}
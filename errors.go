@@ -0,0 +1,291 @@
+package rdsdataapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+var (
+	// ErrNoTransaction is returned when committing or rolling back without an open transaction
+	ErrNoTransaction = errors.New("no open transaction")
+
+	// ErrConnClosed is returned when a connection is used after it was closed
+	ErrConnClosed = errors.New("connection closed")
+
+	// ErrResultTooLarge is returned when the results of a query exceed the Data API's 1MB
+	// response limit, consider QueryPaged to read them in pages.
+	ErrResultTooLarge = errors.New("result too large")
+
+	// ErrUnsupportedParamType is returned for an argument of a type the driver can't send
+	ErrUnsupportedParamType = errors.New("unsupported argument type")
+
+	// ErrDataAPIDisabled is returned when the cluster doesn't have the Data API (its HTTP
+	// endpoint) enabled, see Supported.
+	ErrDataAPIDisabled = errors.New("data api not enabled")
+
+	// ErrUnboundedWrite is returned for an UPDATE or DELETE without a WHERE clause when
+	// 'GuardUnboundedWrites' is set to reject them.
+	ErrUnboundedWrite = errors.New("unbounded write")
+
+	// ErrReadOnly is returned for a statement other than a SELECT, or a transaction that is
+	// not read-only, on a connection configured with 'ReadOnlyConnection'.
+	ErrReadOnly = errors.New("read-only connection")
+
+	// ErrCapacity is returned when Aurora Serverless rejects or times out a statement because
+	// it is scaling or at its max capacity, callers should back off before trying again.
+	ErrCapacity = errors.New("insufficient capacity")
+)
+
+// ErrTransactionExpired is returned when the Data API no longer knows the transaction of
+// the connection, usually because Aurora closed it after it was idle for too long. The
+// connection no longer considers itself in a transaction, so the work can be restarted.
+var ErrTransactionExpired = errors.New("transaction expired")
+
+// isTransactionNotFound returns whether err is the Data API reporting that it doesn't
+// know (anymore) about the transaction id that was send along. The Data API has no
+// dedicated error code for this so the message is inspected.
+func isTransactionNotFound(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case rdsds.ErrCodeBadRequestException, rdsds.ErrCodeNotFoundException:
+	default:
+		return false
+	}
+
+	msg := strings.ToLower(aerr.Message())
+	return strings.Contains(msg, "transaction") &&
+		(strings.Contains(msg, "not found") || strings.Contains(msg, "invalid transaction"))
+}
+
+// errCodeAccessDenied is the code of access denied errors, as reported by IAM and secrets
+// manager. The Data API SDK has no constant for it.
+const errCodeAccessDenied = "AccessDeniedException"
+
+// isNotAuthorized returns whether the (lower-cased) error message says the caller lacks a
+// permission, as opposed to a transient failure.
+func isNotAuthorized(msg string) bool {
+	return strings.Contains(msg, "not authorized") || strings.Contains(msg, "access denied") ||
+		strings.Contains(msg, "is not allowed")
+}
+
+// isAccessDenied returns whether err is a genuine permission error, see isTransientAuth
+// for the access errors that aren't.
+func isAccessDenied(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || isTransientAuth(err) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case errCodeAccessDenied, rdsds.ErrCodeForbiddenException:
+		return true
+	case rdsds.ErrCodeBadRequestException:
+		return isNotAuthorized(strings.ToLower(aerr.Message()))
+	default:
+		return false
+	}
+}
+
+// checkAccessDenied returns an error that points at the policies involved if err is a
+// genuine permission error. Other errors are returned as-is.
+func (c *Conn) checkAccessDenied(err error) error {
+	if !isAccessDenied(err) {
+		return err
+	}
+
+	return fmt.Errorf("access denied, check that the IAM policy of the caller allows the Data API on '%s' and "+
+		"secretsmanager:GetSecretValue on '%s', and that the resource policy of the secret allows it as well: %w", c.resourceARN, c.secretARN, err)
+}
+
+// isResultTooLarge returns whether err is the Data API rejecting a response that exceeds
+// its size limit. It has no dedicated error code for this so the message is inspected.
+func isResultTooLarge(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != rdsds.ErrCodeBadRequestException {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(aerr.Message()), "response size limit")
+}
+
+// isDataAPIDisabled returns whether err is the Data API reporting that it is not enabled for
+// the cluster. It has no dedicated error code for this so the message is inspected.
+func isDataAPIDisabled(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != rdsds.ErrCodeBadRequestException {
+		return false
+	}
+
+	msg := strings.ToLower(aerr.Message())
+	return strings.Contains(msg, "not enabled") &&
+		(strings.Contains(msg, "httpendpoint") || strings.Contains(msg, "http endpoint") || strings.Contains(msg, "data api"))
+}
+
+// DefaultCapacityErrorSubstrings are the (lower-case) substrings of which the message of an
+// error must contain one for it to be classified as a capacity error, unless configured
+// otherwise with CapacityErrorSubstrings.
+var DefaultCapacityErrorSubstrings = []string{"capacity", "scaling"}
+
+// isCapacityError returns whether err is Aurora Serverless failing a statement because it is
+// scaling or at its max capacity. The Data API has no dedicated error codes for this so, as
+// a heuristic, the message must contain one of the substrings (case-insensitive), or one of
+// DefaultCapacityErrorSubstrings if there are none. Secret errors of a resuming cluster and
+// permission errors are never capacity errors, even if their message matches.
+func isCapacityError(err error, substrings []string) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || isTransientAuth(err) || isAccessDenied(err) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case rdsds.ErrCodeBadRequestException, rdsds.ErrCodeServiceUnavailableError,
+		rdsds.ErrCodeStatementTimeoutException, "RequestTimeout", "RequestTimeoutException":
+	default:
+		return false
+	}
+
+	if len(substrings) == 0 {
+		substrings = DefaultCapacityErrorSubstrings
+	}
+
+	msg := strings.ToLower(aerr.Message())
+	for _, s := range substrings {
+		if s != "" && strings.Contains(msg, strings.ToLower(s)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkErr wraps an error of the Data API for a statement in the sentinel error for the
+// condition it indicates, if any.
+func (c *Conn) checkErr(err error) error {
+	if isResultTooLarge(err) {
+		return &sentinelError{ErrResultTooLarge, fmt.Sprintf("%v: %v", ErrResultTooLarge, err), err}
+	}
+
+	if isDataAPIDisabled(err) {
+		return &sentinelError{ErrDataAPIDisabled, fmt.Sprintf("%v for '%s', enable it in the cluster settings (e.g. with 'aws rds modify-db-cluster --enable-http-endpoint'): %v", ErrDataAPIDisabled, c.resourceARN, err), err}
+	}
+
+	if isCapacityError(err, c.cfg.CapacityErrorSubstrings) {
+		return &sentinelError{ErrCapacity, fmt.Sprintf("%v, the cluster is scaling or at its max capacity: %v", ErrCapacity, err), err}
+	}
+
+	return databaseError(c.checkAccessDenied(c.checkTransactionExpired(err)))
+}
+
+// sentinelError is an error of the Data API that was recognized as one of the sentinel
+// errors. It matches the sentinel with errors.Is and unwraps to the original error, such that
+// errors.As still finds its awserr.Error.
+type sentinelError struct {
+	sentinel error
+	msg      string
+	err      error
+}
+
+func (e *sentinelError) Error() string        { return e.msg }
+func (e *sentinelError) Unwrap() error        { return e.err }
+func (e *sentinelError) Is(target error) bool { return target == e.sentinel }
+
+// DatabaseError is returned for statements that the database engine itself rejected, with
+// the codes that the Data API included in its error message. Use errors.As to get to it.
+type DatabaseError struct {
+	SQLState   string // the five character SQL state, e.g. "23505" for a postgres unique violation
+	EngineCode int    // the engine specific error code, e.g. 1062 for a MySQL duplicate entry
+	Err        error  // the error as returned by the Data API
+}
+
+func (e *DatabaseError) Error() string { return e.Err.Error() }
+func (e *DatabaseError) Unwrap() error { return e.Err }
+
+var (
+	sqlStateExp   = regexp.MustCompile(`(?i)SQLState:?\s*([0-9A-Z]{5})\b`)
+	engineCodeExp = regexp.MustCompile(`(?i)error code:?\s*(\d+)`)
+)
+
+// databaseError wraps err in a DatabaseError if its message holds a SQL state or engine
+// error code, as the Data API reports them in these formats:
+//
+//	Database error code: 1062. Message: Duplicate entry '1' for key 'PRIMARY'
+//	ERROR: duplicate key value violates unique constraint "foo_pkey"; SQLState: 23505
+//
+// Other errors are returned as-is.
+func databaseError(err error) error {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return err
+	}
+
+	dberr := &DatabaseError{Err: err}
+	if m := sqlStateExp.FindStringSubmatch(aerr.Message()); m != nil {
+		dberr.SQLState = strings.ToUpper(m[1])
+	}
+
+	if m := engineCodeExp.FindStringSubmatch(aerr.Message()); m != nil {
+		dberr.EngineCode, _ = strconv.Atoi(m[1])
+	}
+
+	if dberr.SQLState == "" && dberr.EngineCode == 0 {
+		return err
+	}
+
+	return dberr
+}
+
+// IsDuplicateKey returns whether err is the engine rejecting a statement because it would
+// duplicate a unique key: MySQL's error 1062 or postgres' SQL state 23505.
+func IsDuplicateKey(err error) bool {
+	var dberr *DatabaseError
+	if !errors.As(err, &dberr) {
+		return false
+	}
+
+	return dberr.EngineCode == 1062 || dberr.SQLState == "23505"
+}
+
+// TxError is returned when committing or rolling back a transaction failed. It reports whether
+// the connection still considers itself in the transaction: if the Data API no longer
+// knows the transaction, Active is false and Err wraps ErrTransactionExpired. Otherwise the
+// transaction is kept, so committing or rolling back can be retried with the driver
+// connection (see sql.Conn.Raw).
+type TxError struct {
+	Op            string // either "commit" or "rollback"
+	TransactionID string
+	Active        bool // whether the connection still holds the transaction
+	Err           error
+}
+
+func (e *TxError) Error() string { return fmt.Sprintf("failed to %s transaction: %v", e.Op, e.Err) }
+func (e *TxError) Unwrap() error { return e.Err }
+
+// txError returns the error for a failed commit or rollback, clearing the transaction of
+// the connection if err indicates it no longer exists.
+func (c *Conn) txError(op string, err error) error {
+	id := c.transactionID
+	err = c.checkTransactionExpired(err)
+	return &TxError{Op: op, TransactionID: id, Active: c.transactionID != "", Err: err}
+}
+
+// checkTransactionExpired clears the transaction of the connection if err indicates it
+// has expired and returns an error wrapping ErrTransactionExpired. Other errors are
+// returned as-is.
+func (c *Conn) checkTransactionExpired(err error) error {
+	if c.transactionID == "" || !isTransactionNotFound(err) {
+		return err
+	}
+
+	c.transactionID, c.txCtx, c.txResource = "", nil, resource{}
+	return &sentinelError{ErrTransactionExpired, fmt.Sprintf("%v: %v", ErrTransactionExpired, err), err}
+}
@@ -0,0 +1,253 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestTransactionExpired(t *testing.T) {
+	notFound := awserr.New(rdsds.ErrCodeBadRequestException, "Transaction AQC5aWma3a is not found", nil)
+	m := &mockAPI{
+		ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+			return nil, notFound
+		},
+		CommitTransaction: func(in *rdsds.CommitTransactionInput) (*rdsds.CommitTransactionOutput, error) {
+			return nil, notFound
+		},
+	}
+
+	c, ctx := mockConn(t, m), WithResource(context.Background(), "arn:other", "arn:othersec")
+	for _, fn := range []func() error{
+		func() error { _, err := c.ExecContext(ctx, "DELETE FROM foo", nil); return err },
+		c.Commit,
+	} {
+		if _, err := c.BeginTx(ctx, driver.TxOptions{}); err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+
+		var aerr awserr.Error
+		if err := fn(); !errors.Is(err, ErrTransactionExpired) || !errors.As(err, &aerr) || aerr.Code() != rdsds.ErrCodeBadRequestException {
+			t.Fatalf("expected transaction expired error that wraps the aws error, got: %v", err)
+		}
+
+		if c.transactionID != "" || c.txResource != (resource{}) {
+			t.Fatalf("expected the stale transaction id and resource to be cleared")
+		}
+	}
+
+	// other errors should leave the transaction in place
+	m.CommitTransaction = func(in *rdsds.CommitTransactionInput) (*rdsds.CommitTransactionOutput, error) {
+		return nil, awserr.New(rdsds.ErrCodeInternalServerErrorException, "internal", nil)
+	}
+
+	if _, err := c.BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	if err := c.Commit(); err == nil || errors.Is(err, ErrTransactionExpired) || c.transactionID == "" {
+		t.Fatalf("expected other errors to keep the transaction, got: %v", err)
+	}
+}
+
+func TestTxError(t *testing.T) {
+	var rerr error
+	m := &mockAPI{
+		CommitTransaction: func(in *rdsds.CommitTransactionInput) (*rdsds.CommitTransactionOutput, error) {
+			return nil, awserr.New(rdsds.ErrCodeInternalServerErrorException, "internal", nil)
+		},
+		RollbackTransaction: func(in *rdsds.RollbackTransactionInput) (*rdsds.RollbackTransactionOutput, error) {
+			return nil, rerr
+		},
+	}
+
+	c := mockConn(t, m)
+	if _, err := c.BeginTx(context.Background(), driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	var txerr *TxError
+	if err := c.Commit(); !errors.As(err, &txerr) || !txerr.Active || txerr.Op != "commit" || txerr.TransactionID != "tx1" {
+		t.Fatalf("expected retryable commit error to keep the transaction active, got: %+v", err)
+	}
+
+	rerr = awserr.New(rdsds.ErrCodeBadRequestException, "Transaction tx1 is not found", nil)
+	if err := c.Rollback(); !errors.As(err, &txerr) || txerr.Active || !errors.Is(err, ErrTransactionExpired) || c.transactionID != "" {
+		t.Fatalf("expected terminal rollback error to clear the transaction, got: %+v", err)
+	}
+}
+
+func TestAccessDeniedClassification(t *testing.T) {
+	for i, c := range []struct {
+		code      string
+		msg       string
+		transient bool
+		denied    bool
+	}{
+		{rdsds.ErrCodeBadRequestException, "Error fetching secret arn:sec : timed out", true, false},
+		{errCodeAccessDenied, "Unable to fetch secret, please try again", true, false},
+		{errCodeAccessDenied, "User is not authorized to perform: secretsmanager:GetSecretValue on secret arn:sec", false, true},
+		{rdsds.ErrCodeForbiddenException, "Forbidden", false, true},
+		{rdsds.ErrCodeBadRequestException, "Access denied for user 'admin'", false, true},
+		{rdsds.ErrCodeBadRequestException, "Communications link failure", false, false},
+		{"ThrottlingException", "timeout fetching secret", false, false},
+	} {
+		err := awserr.New(c.code, c.msg, nil)
+		if isTransientAuth(err) != c.transient || isAccessDenied(err) != c.denied {
+			t.Fatalf("%d: expected transient=%v and denied=%v, got: %v and %v", i, c.transient, c.denied, isTransientAuth(err), isAccessDenied(err))
+		}
+	}
+}
+
+func TestTransientAuthRetry(t *testing.T) {
+	n := 0
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if n++; n <= transientAuthRetries {
+			return nil, awserr.New(rdsds.ErrCodeBadRequestException, "Error fetching secret: timed out", nil)
+		}
+
+		return nil, awserr.New(errCodeAccessDenied, "not authorized to perform secretsmanager:GetSecretValue", nil)
+	}}
+
+	c := mockConn(t, m)
+	c.retryer = instantRetryer(0)
+	_, err := c.ExecContext(context.Background(), "DELETE FROM foo", nil)
+	if err == nil || !strings.Contains(err.Error(), "resource policy of the secret") {
+		t.Fatalf("expected a permission error pointing at the policies, got: %v", err)
+	}
+
+	if len(m.executes) != transientAuthRetries+1 {
+		t.Fatalf("expected transient errors to be retried, got: %d calls", len(m.executes))
+	}
+}
+
+func TestCapacityClassification(t *testing.T) {
+	for i, c := range []struct {
+		code       string
+		msg        string
+		substrings []string
+		capacity   bool
+	}{
+		{rdsds.ErrCodeBadRequestException, "The database is currently scaling, please retry", nil, true},
+		{"RequestTimeout", "Aurora Serverless reached its maximum capacity", nil, true},
+		{rdsds.ErrCodeStatementTimeoutException, "Timed out waiting for capacity", nil, true},
+		{rdsds.ErrCodeBadRequestException, "Communications link failure", nil, false},
+		{rdsds.ErrCodeBadRequestException, "Error fetching secret while scaling: timed out", nil, false},
+		{errCodeAccessDenied, "not authorized to scale capacity", nil, false},
+		{"ThrottlingException", "at max capacity", nil, false},
+		{rdsds.ErrCodeBadRequestException, "Cluster is SCALING", []string{"Scaling"}, true},
+		{rdsds.ErrCodeBadRequestException, "No capacity left", []string{"acu limit"}, false},
+	} {
+		if isCapacityError(awserr.New(c.code, c.msg, nil), c.substrings) != c.capacity {
+			t.Fatalf("%d: expected capacity=%v", i, c.capacity)
+		}
+	}
+}
+
+func TestCapacityRetry(t *testing.T) {
+	n := 0
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		n++
+		return nil, awserr.New(rdsds.ErrCodeBadRequestException, "The database is scaling", nil)
+	}}
+
+	c := mockConn(t, m)
+	c.retryer = instantRetryer(0)
+	if _, err := c.ExecContext(context.Background(), "DELETE FROM foo", nil); !errors.Is(err, ErrCapacity) {
+		t.Fatalf("expected a capacity error, got: %v", err)
+	}
+
+	if n != 1 {
+		t.Fatalf("expected capacity errors not to be retried by default, got: %d calls", n)
+	}
+
+	n, c.retryer = 0, instantRetryer(0)
+	c.retryer.capacity = 2
+	if _, err := c.ExecContext(context.Background(), "DELETE FROM foo", nil); !errors.Is(err, ErrCapacity) {
+		t.Fatalf("expected a capacity error, got: %v", err)
+	}
+
+	if n != 3 {
+		t.Fatalf("expected capacity errors to be retried twice, got: %d calls", n)
+	}
+
+	var aerr awserr.Error
+	if _, err := c.ExecContext(context.Background(), "DELETE FROM foo", nil); !errors.As(err, &aerr) || aerr.Code() != rdsds.ErrCodeBadRequestException {
+		t.Fatalf("expected the capacity error to wrap the aws error, got: %v", err)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return nil, awserr.New(rdsds.ErrCodeBadRequestException, "Database returned more than the allowed response size limit", nil)
+	}}
+
+	c := mockConn(t, m)
+	var aerr awserr.Error
+	if _, err := c.QueryContext(context.Background(), "SELECT * FROM big", nil); !errors.Is(err, ErrResultTooLarge) || !errors.As(err, &aerr) {
+		t.Fatalf("expected result too large that wraps the aws error, got: %v", err)
+	}
+
+	if _, err := c.QueryContext(context.Background(), "SELECT 1", []driver.NamedValue{{Name: "a", Value: struct{}{}}}); !errors.Is(err, ErrUnsupportedParamType) {
+		t.Fatalf("expected unsupported param type, got: %v", err)
+	}
+
+	if err := c.Commit(); !errors.Is(err, ErrNoTransaction) {
+		t.Fatalf("expected no transaction, got: %v", err)
+	}
+
+	if err := c.Rollback(); !errors.Is(err, ErrNoTransaction) {
+		t.Fatalf("expected no transaction, got: %v", err)
+	}
+
+	c.Close()
+	if _, err := c.QueryContext(context.Background(), "SELECT 1", nil); !errors.Is(err, ErrConnClosed) {
+		t.Fatalf("expected conn closed, got: %v", err)
+	}
+
+	if _, err := c.PrepareContext(context.Background(), "SELECT 1"); !errors.Is(err, ErrConnClosed) {
+		t.Fatalf("expected conn closed, got: %v", err)
+	}
+}
+
+func TestDatabaseError(t *testing.T) {
+	for i, c := range []struct {
+		msg      string
+		expState string
+		expCode  int
+		expDup   bool
+	}{
+		{"Database error code: 1062. Message: Duplicate entry '1' for key 'PRIMARY'", "", 1062, true},
+		{"ERROR: duplicate key value violates unique constraint \"foo_pkey\"\n  Detail: Key (id)=(1) already exists.; SQLState: 23505", "23505", 0, true},
+		{"ERROR: relation \"foo\" does not exist\n  Position: 15; SQLState: 42P01", "42P01", 0, false},
+		{"Database error code: 1146. Message: Table 'db.foo' doesn't exist", "", 1146, false},
+		{"Communications link failure", "", 0, false},
+	} {
+		m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+			return nil, awserr.New(rdsds.ErrCodeBadRequestException, c.msg, nil)
+		}}
+
+		_, err := mockConn(t, m).ExecContext(context.Background(), "INSERT INTO foo VALUES (1)", nil)
+		if IsDuplicateKey(err) != c.expDup {
+			t.Fatalf("%d: expected duplicate key to be %v, got: %v", i, c.expDup, err)
+		}
+
+		var dberr *DatabaseError
+		if !errors.As(err, &dberr) {
+			if c.expState != "" || c.expCode != 0 {
+				t.Fatalf("%d: expected a database error, got: %v", i, err)
+			}
+
+			continue
+		}
+
+		if dberr.SQLState != c.expState || dberr.EngineCode != c.expCode || !strings.Contains(err.Error(), c.msg) {
+			t.Fatalf("%d: expected state '%s' and code %d, got: %+v", i, c.expState, c.expCode, dberr)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// GuardLog logs UPDATE and DELETE statements that have no WHERE clause
+	GuardLog = "log"
+
+	// GuardReject rejects UPDATE and DELETE statements that have no WHERE clause
+	GuardReject = "reject"
+)
+
+// isUnboundedWrite returns whether the statement is an UPDATE or DELETE without a top-level
+// WHERE clause, which affects every row of the table. A WHERE clause of a sub-query doesn't
+// bound the statement itself.
+func isUnboundedWrite(engine, query string, kind StatementKind) bool {
+	if kind != StatementUpdate && kind != StatementDelete {
+		return false
+	}
+
+	for _, w := range topLevelWords(engine, query) {
+		if strings.EqualFold(w, "WHERE") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// guardReadOnly rejects statements that are not a SELECT if the connection is configured to
+// be read-only, unless the driver itself executes them with a context from withDriverStatement.
+// This is enforced by the driver only, based on ClassifyStatement.
+func (c *Conn) guardReadOnly(ctx context.Context, kind StatementKind) error {
+	if !c.cfg.ReadOnlyConnection || kind == StatementSelect || driverStatementFromContext(ctx) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s statement rejected, only SELECT statements can be executed ('ReadOnlyConnection')", ErrReadOnly, kind)
+}
+
+// guardWrite applies the configured GuardUnboundedWrites mode to the statement, unless
+// the context allows unbounded writes. Prepared statements are guarded when prepared.
+func (c *Conn) guardWrite(ctx context.Context, query string, a analysis) error {
+	if c.cfg.GuardUnboundedWrites == "" || unboundedWriteFromContext(ctx) || !a.unbounded {
+		return nil
+	}
+
+	if c.cfg.GuardUnboundedWrites == GuardLog {
+		c.logf("%s statement without a WHERE clause affects all rows: %s", a.kind, query)
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s statement without a WHERE clause, use WithUnboundedWrite if it should affect all rows", ErrUnboundedWrite, a.kind)
+}
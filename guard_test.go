@@ -0,0 +1,126 @@
+package rdsdataapi
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsUnboundedWrite(t *testing.T) {
+	for i, c := range []struct {
+		engine, query string
+		exp           bool
+	}{
+		{"", "DELETE FROM foo", true},
+		{"", "delete from foo where id = 1", false},
+		{"", "UPDATE foo SET a = 1", true},
+		{"", "UPDATE foo SET a = (SELECT b FROM bar WHERE id = 1)", true},
+		{"", "UPDATE foo SET a = 'WHERE'", true},
+		{"", "WITH x AS (SELECT id FROM bar) DELETE FROM foo WHERE id IN (SELECT id FROM x)", false},
+		{"", "SELECT * FROM foo", false},
+		{"", "INSERT INTO foo VALUES (1)", false},
+		{EnginePostgres, "UPDATE foo SET a = 'x\\' WHERE id = 1 -- '", false},
+		{EngineMySQL, "UPDATE foo SET a = 'x\\' WHERE id = 1 -- '", true},
+		{EnginePostgres, "UPDATE foo SET a = E'x\\' WHERE id = 1'", true},
+	} {
+		if act := isUnboundedWrite(c.engine, c.query, classifyStatement(c.engine, c.query)); act != c.exp {
+			t.Fatalf("%d: expected unbounded to be %v for '%s', got: %v", i, c.exp, c.query, act)
+		}
+	}
+}
+
+func TestGuardUnboundedWrites(t *testing.T) {
+	m := &mockAPI{}
+	c := mockConn(t, m)
+	ctx := context.Background()
+	if _, err := c.ExecContext(ctx, "DELETE FROM foo", nil); err != nil {
+		t.Fatalf("expected unbounded writes to be allowed by default, got: %v", err)
+	}
+
+	c.cfg.GuardUnboundedWrites = GuardReject
+	if _, err := c.ExecContext(ctx, "DELETE FROM foo", nil); !errors.Is(err, ErrUnboundedWrite) {
+		t.Fatalf("expected unbounded write to be rejected, got: %v", err)
+	}
+
+	if _, err := c.PrepareContext(ctx, "UPDATE foo SET a = :a"); !errors.Is(err, ErrUnboundedWrite) {
+		t.Fatalf("expected unbounded prepared write to be rejected, got: %v", err)
+	}
+
+	if _, err := c.ExecContext(WithUnboundedWrite(ctx), "DELETE FROM foo", nil); err != nil {
+		t.Fatalf("expected context to allow the unbounded write, got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	c.cfg.GuardUnboundedWrites = GuardLog
+	if _, err := c.ExecContext(ctx, "UPDATE foo SET a = 1", nil); err != nil || !strings.Contains(buf.String(), "without a WHERE clause") {
+		t.Fatalf("expected unbounded write to be logged, got: %v and '%s'", err, buf.String())
+	}
+
+	c.cfg.GuardUnboundedWrites, c.cfg.Engine = GuardReject, EnginePostgres
+	if _, err := c.ExecContext(ctx, "UPDATE foo SET a = 'x\\' WHERE id = 1 -- '", nil); err != nil {
+		t.Fatalf("expected the write to be guarded with the syntax of the engine, got: %v", err)
+	}
+
+	if len(m.executes) != 4 {
+		t.Fatalf("expected only the allowed statements to be executed, got: %d", len(m.executes))
+	}
+}
+
+func TestReadOnlyConnection(t *testing.T) {
+	m := &mockAPI{}
+	cfg := testCfg
+	cfg.ReadOnlyConnection, cfg.Engine, cfg.StatementTimeout = true, EngineMySQL, time.Second
+	conn, err := newConnector(cfg, m).Connect(context.Background())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	c := conn.(*Conn)
+	for _, q := range []string{"INSERT INTO foo VALUES (1)", "UPDATE foo SET a = 1 WHERE id = 1", "DROP TABLE foo", "SET @a = 1", "SELECT * INTO bar FROM foo"} {
+		if _, err = c.ExecContext(context.Background(), q, nil); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("expected '%s' to be rejected, got: %v", q, err)
+		}
+
+		if _, err = c.PrepareContext(context.Background(), q); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("expected preparing '%s' to be rejected, got: %v", q, err)
+		}
+	}
+
+	if len(m.executes) != 0 {
+		t.Fatalf("expected rejected statements not to reach the Data API, got: %d calls", len(m.executes))
+	}
+
+	if _, err = c.QueryContext(context.Background(), "SELECT * FROM foo", nil); err != nil {
+		t.Fatalf("expected a SELECT to be executed, got: %v", err)
+	}
+
+	if _, err = c.BeginTx(context.Background(), driver.TxOptions{}); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected a transaction that may write to be rejected, got: %v", err)
+	}
+
+	if _, err = c.BeginTx(context.Background(), driver.TxOptions{ReadOnly: true}); err != nil {
+		t.Fatalf("expected a read-only transaction to begin, got: %v", err)
+	}
+
+	if len(m.executes) != 2 || !strings.HasPrefix(*m.executes[1].Sql, "SET SESSION MAX_EXECUTION_TIME") {
+		t.Fatalf("expected the driver to set the statement timeout, got: %d calls", len(m.executes))
+	}
+
+	if err = c.Rollback(); err != nil {
+		t.Fatalf("failed to rollback: %v", err)
+	}
+
+	c.cfg.ImplicitTx = true
+	if _, err = c.ExecContext(context.Background(), "DELETE FROM foo WHERE id = 1", nil); !errors.Is(err, ErrReadOnly) || len(m.begins) != 1 {
+		t.Fatalf("expected the statement to be rejected before an implicit transaction, got: %v and %d begins", err, len(m.begins))
+	}
+}
@@ -0,0 +1,79 @@
+package rdsdataapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// Operations that are reported to ObserveLatency of a MetricsCollector
+const (
+	OpExecute  = "execute"
+	OpBatch    = "batch"
+	OpBegin    = "begin"
+	OpCommit   = "commit"
+	OpRollback = "rollback"
+)
+
+// MetricsCollector receives the activity of the driver, e.g. to expose it as Prometheus
+// metrics. Implementations must be safe for concurrent use as a collector is shared by all
+// connections of a connector.
+type MetricsCollector interface {
+	IncExec()                                  // a statement is executed
+	IncBatch()                                 // a batch of prepared statements is executed
+	IncRetry()                                 // a failed call to the Data API is retried
+	ObserveLatency(op string, d time.Duration) // an operation finished, including any retries
+	IncError(code string)                      // an operation failed, code is the AWS error code if any
+}
+
+// observe counts the operation that began at start if it succeeded and reports its outcome
+// to the configured metrics collector, if any.
+func (c *Conn) observe(op string, start time.Time, err error) {
+	if err == nil {
+		c.counters().inc(opStats[op])
+	}
+
+	m := c.cfg.Metrics
+	if m == nil {
+		return
+	}
+
+	m.ObserveLatency(op, c.clock().Now().Sub(start))
+	if err != nil {
+		m.IncError(errorCode(err))
+	}
+}
+
+// checkSlow reports the statement if it began longer than the SlowQueryThreshold ago
+func (c *Conn) checkSlow(ctx context.Context, query string, params []*rdsds.SqlParameter, start time.Time) {
+	if c.cfg.SlowQueryThreshold <= 0 {
+		return
+	}
+
+	d := c.clock().Now().Sub(start)
+	if d <= c.cfg.SlowQueryThreshold {
+		return
+	}
+
+	ds := c.describeParams(ctx, params)
+	if c.cfg.SlowQueryFunc != nil {
+		c.cfg.SlowQueryFunc(ctx, query, d, ds)
+		return
+	}
+
+	c.logf("slow statement took %s with %d parameters [%s]: %s", d, len(ds), strings.Join(ds, ", "), query)
+}
+
+// errorCode returns the AWS error code of err, or an empty string if it has none
+func errorCode(err error) string {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return ""
+	}
+
+	return aerr.Code()
+}
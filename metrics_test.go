@@ -0,0 +1,89 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// recordingMetrics records all calls made to it as strings
+type recordingMetrics struct{ calls []string }
+
+func (m *recordingMetrics) IncExec()                                  { m.calls = append(m.calls, "exec") }
+func (m *recordingMetrics) IncBatch()                                 { m.calls = append(m.calls, "batch") }
+func (m *recordingMetrics) IncRetry()                                 { m.calls = append(m.calls, "retry") }
+func (m *recordingMetrics) ObserveLatency(op string, d time.Duration) { m.calls = append(m.calls, op) }
+func (m *recordingMetrics) IncError(code string)                      { m.calls = append(m.calls, "error:"+code) }
+
+func TestMetricsCollector(t *testing.T) {
+	rec := &recordingMetrics{}
+	m := failingAPI(3, "ThrottlingException")
+	conn := mockConn(t, m)
+	conn.cfg.Metrics = rec
+	conn.retryer = instantRetryer(1)
+	conn.retryer.metrics = rec
+
+	ctx := context.Background()
+	if _, err := conn.QueryContext(ctx, "SELECT 1", nil); err == nil {
+		t.Fatalf("expected statement to fail after retrying")
+	}
+
+	if _, err := conn.BeginTx(ctx, driver.TxOptions{}); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	s, _ := conn.PrepareContext(ctx, "INSERT INTO foo VALUES (:id)")
+	if _, err := s.(*Stmt).ExecContext(ctx, []driver.NamedValue{{Name: "id", Value: int64(1)}}); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	if err := conn.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	exp := []string{"exec", "retry", OpExecute, "error:ThrottlingException", OpBegin, "batch", OpBatch, OpCommit}
+	if !reflect.DeepEqual(rec.calls, exp) {
+		t.Fatalf("expected metrics calls %v, got: %v", exp, rec.calls)
+	}
+}
+
+func TestSlowQuery(t *testing.T) {
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if aws.StringValue(in.Sql) == "SELECT SLEEP(1)" {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}}
+
+	var slow []string
+	conn := mockConn(t, m)
+	conn.cfg.SlowQueryThreshold = time.Millisecond
+	conn.cfg.SlowQueryFunc = func(ctx context.Context, sql string, d time.Duration, params []string) {
+		if d <= time.Millisecond || !reflect.DeepEqual(params, []string{":a=<long>"}) {
+			t.Fatalf("expected slow query with a single redacted parameter, got: %v and %v", d, params)
+		}
+
+		slow = append(slow, sql)
+	}
+
+	args := []driver.NamedValue{{Name: "a", Value: int64(1)}}
+	for _, q := range []string{"SELECT 1", "SELECT SLEEP(1)"} {
+		if _, err := conn.QueryContext(context.Background(), q, args); err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+	}
+
+	if !reflect.DeepEqual(slow, []string{"SELECT SLEEP(1)"}) {
+		t.Fatalf("expected only the slow query to be reported, got: %v", slow)
+	}
+}
@@ -0,0 +1,112 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// mockAPI is a mock of the Data API, each method calls the function field of the same
+// name if it is set and returns an empty output otherwise. All inputs are recorded, it is
+// safe for concurrent use if the function fields are.
+type mockAPI struct {
+	ExecuteStatement      func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error)
+	BatchExecuteStatement func(in *rdsds.BatchExecuteStatementInput) (*rdsds.BatchExecuteStatementOutput, error)
+	BeginTransaction      func(in *rdsds.BeginTransactionInput) (*rdsds.BeginTransactionOutput, error)
+	CommitTransaction     func(in *rdsds.CommitTransactionInput) (*rdsds.CommitTransactionOutput, error)
+	RollbackTransaction   func(in *rdsds.RollbackTransactionInput) (*rdsds.RollbackTransactionOutput, error)
+
+	executes  []*rdsds.ExecuteStatementInput
+	batches   []*rdsds.BatchExecuteStatementInput
+	begins    []*rdsds.BeginTransactionInput
+	commits   []*rdsds.CommitTransactionInput
+	rollbacks []*rdsds.RollbackTransactionInput
+	lastCtx   aws.Context // context of the last call
+
+	mu sync.Mutex
+}
+
+func (m *mockAPI) ExecuteStatementWithContext(ctx aws.Context, in *rdsds.ExecuteStatementInput, _ ...request.Option) (*rdsds.ExecuteStatementOutput, error) {
+	m.mu.Lock()
+	m.lastCtx = ctx
+	m.executes = append(m.executes, in)
+	m.mu.Unlock()
+	if m.ExecuteStatement == nil {
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}
+
+	return m.ExecuteStatement(in)
+}
+
+func (m *mockAPI) BatchExecuteStatementWithContext(ctx aws.Context, in *rdsds.BatchExecuteStatementInput, _ ...request.Option) (*rdsds.BatchExecuteStatementOutput, error) {
+	m.mu.Lock()
+	m.lastCtx = ctx
+	m.batches = append(m.batches, in)
+	m.mu.Unlock()
+	if m.BatchExecuteStatement == nil {
+		return &rdsds.BatchExecuteStatementOutput{}, nil
+	}
+
+	return m.BatchExecuteStatement(in)
+}
+
+func (m *mockAPI) BeginTransactionWithContext(ctx aws.Context, in *rdsds.BeginTransactionInput, _ ...request.Option) (*rdsds.BeginTransactionOutput, error) {
+	m.mu.Lock()
+	m.lastCtx = ctx
+	m.begins = append(m.begins, in)
+	m.mu.Unlock()
+	if m.BeginTransaction == nil {
+		return &rdsds.BeginTransactionOutput{TransactionId: aws.String("tx1")}, nil
+	}
+
+	return m.BeginTransaction(in)
+}
+
+func (m *mockAPI) CommitTransactionWithContext(ctx aws.Context, in *rdsds.CommitTransactionInput, _ ...request.Option) (*rdsds.CommitTransactionOutput, error) {
+	m.mu.Lock()
+	m.lastCtx = ctx
+	m.commits = append(m.commits, in)
+	m.mu.Unlock()
+	if m.CommitTransaction == nil {
+		return &rdsds.CommitTransactionOutput{}, nil
+	}
+
+	return m.CommitTransaction(in)
+}
+
+func (m *mockAPI) RollbackTransactionWithContext(ctx aws.Context, in *rdsds.RollbackTransactionInput, _ ...request.Option) (*rdsds.RollbackTransactionOutput, error) {
+	m.mu.Lock()
+	m.lastCtx = ctx
+	m.rollbacks = append(m.rollbacks, in)
+	m.mu.Unlock()
+	if m.RollbackTransaction == nil {
+		return &rdsds.RollbackTransactionOutput{}, nil
+	}
+
+	return m.RollbackTransaction(in)
+}
+
+// testCfg is a valid configuration to use with the mocked api
+var testCfg = Config{Database: "db", ResourceARN: "arn:res", SecretARN: "arn:sec", Region: "eu-west-1"}
+
+// mockDB returns a database handle that uses the mocked api through a connector
+func mockDB(tb testing.TB, m *mockAPI) *sql.DB {
+	db := sql.OpenDB(newConnector(testCfg, m))
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+// mockConn returns a single connection that uses the mocked api
+func mockConn(tb testing.TB, m *mockAPI) *Conn {
+	c, err := newConnector(testCfg, m).Connect(context.Background())
+	if err != nil {
+		tb.Fatalf("failed to connect: %v", err)
+	}
+
+	return c.(*Conn)
+}
@@ -0,0 +1,197 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// PageKeyParam is the name of the parameter that QueryPaged binds the last key of the
+// previous page to, it must not be used by the paged query itself.
+const PageKeyParam = "pagedLastKey"
+
+// FetchFunc returns the query, and its arguments, that selects the page of rows following
+// the row with lastKey. lastKey is nil for the first page. Returning an empty query ends
+// the iteration.
+type FetchFunc func(lastKey interface{}) (query string, args []interface{})
+
+// PagedRows iterates over the results of a query that is read in pages. It is used like
+// *sql.Rows: call Next before each Scan and check Err when Next returns false.
+type PagedRows struct {
+	ctx   context.Context
+	db    Queryer
+	fetch FetchFunc
+
+	keyCols  []string
+	pageSize int // the last page is the first partial one, or the first empty one if zero
+
+	rows    *sql.Rows
+	cols    []string
+	keyIdx  []int
+	lastKey interface{}
+	pageKey interface{} // the key that the current page was fetched with
+	nPage   int         // nr of rows read from the current page
+	done    bool
+	err     error
+}
+
+// QueryPaged reads the results of query in pages of pageSize rows, ordered by keyCol,
+// such that results that are larger then the Data API's 1MB response limit can be read.
+// keyCol must be a unique column of the results. The query is wrapped as follows:
+//
+//	SELECT * FROM (<query>) AS paged WHERE <keyCol> > :pagedLastKey ORDER BY <keyCol> LIMIT <pageSize>
+//
+// The key column is not quoted, so it must not come from untrusted input.
+func QueryPaged(ctx context.Context, db Queryer, query, keyCol string, pageSize int, args ...interface{}) *PagedRows {
+	if pageSize < 1 {
+		return &PagedRows{err: fmt.Errorf("page size must be at least 1, got: %d", pageSize)}
+	}
+
+	first := fmt.Sprintf("SELECT * FROM (%s) AS paged ORDER BY %s LIMIT %d", query, keyCol, pageSize)
+	next := fmt.Sprintf("SELECT * FROM (%s) AS paged WHERE %s > :%s ORDER BY %s LIMIT %d", query, keyCol, PageKeyParam, keyCol, pageSize)
+
+	return &PagedRows{ctx: ctx, db: db, keyCols: []string{keyCol}, pageSize: pageSize,
+		fetch: func(lastKey interface{}) (string, []interface{}) {
+			if lastKey == nil {
+				return first, args
+			}
+
+			return next, append(append([]interface{}{}, args...), sql.Named(PageKeyParam, lastKey))
+		}}
+}
+
+// QueryFetch reads results in pages that are selected by the queries of fetch, for cursors
+// that QueryPaged doesn't support such as composite keys or a custom ordering. fetch is
+// called with the key of the last row that was read, which is the value of the key column
+// if there is one, or else a []interface{} with the values of the key columns in order.
+// The iteration ends at the first empty page. The queries must order the rows by their key
+// such that each page continues after lastKey, a page whose last row has the key the page
+// was fetched with is reported as an error.
+func QueryFetch(ctx context.Context, db Queryer, fetch FetchFunc, keyCols ...string) *PagedRows {
+	if len(keyCols) < 1 {
+		return &PagedRows{err: fmt.Errorf("at least one key column is required")}
+	}
+
+	return &PagedRows{ctx: ctx, db: db, fetch: fetch, keyCols: keyCols}
+}
+
+// Next prepares the next row for reading with Scan, fetching the next page if the current
+// page is exhausted. It returns false when there are no more rows or an error occurred.
+func (r *PagedRows) Next() bool {
+	for !r.done && r.err == nil {
+		if r.rows == nil {
+			r.fetchPage()
+			continue
+		}
+
+		if r.rows.Next() {
+			r.nPage++
+			r.err = r.readKey()
+			return r.err == nil
+		}
+
+		if r.err = r.rows.Err(); r.err != nil {
+			r.err = fmt.Errorf("failed to read page after key '%v': %w", r.pageKey, r.err)
+			return false
+		}
+
+		r.rows.Close()
+		r.rows, r.done = nil, r.nPage == 0 || r.nPage < r.pageSize // a partial (or empty) page is the last one
+		if !r.done && reflect.DeepEqual(r.lastKey, r.pageKey) {
+			r.err = fmt.Errorf("page after key '%v' did not advance the key, the query must select the rows that follow it", r.pageKey)
+		}
+	}
+
+	return false
+}
+
+// fetchPage queries the page following the last key
+func (r *PagedRows) fetchPage() {
+	query, args := r.fetch(r.lastKey)
+	if query == "" {
+		r.done = true
+		return
+	}
+
+	if r.rows, r.err = r.db.QueryContext(r.ctx, query, args...); r.err != nil {
+		r.err = fmt.Errorf("failed to query page after key '%v': %w", r.lastKey, r.err)
+		return
+	}
+
+	r.nPage, r.pageKey = 0, r.lastKey
+	if r.cols != nil {
+		return
+	}
+
+	if r.cols, r.err = r.rows.Columns(); r.err != nil {
+		return
+	}
+
+	r.keyIdx = make([]int, len(r.keyCols))
+	for i, kc := range r.keyCols {
+		r.keyIdx[i] = -1
+		for j, c := range r.cols {
+			if c == kc {
+				r.keyIdx[i] = j
+			}
+		}
+
+		if r.keyIdx[i] < 0 {
+			r.err = fmt.Errorf("key column '%s' is not part of the results", kc)
+			return
+		}
+	}
+}
+
+// readKey remembers the key of the current row for fetching the next page
+func (r *PagedRows) readKey() error {
+	vals := make([]interface{}, len(r.cols))
+	for i := range vals {
+		vals[i] = new(interface{})
+	}
+
+	if err := r.rows.Scan(vals...); err != nil {
+		return fmt.Errorf("failed to read key column: %w", err)
+	}
+
+	key := make([]interface{}, len(r.keyIdx))
+	for i, idx := range r.keyIdx {
+		if key[i] = *(vals[idx].(*interface{})); key[i] == nil {
+			return fmt.Errorf("key column '%s' must not be NULL", r.keyCols[i])
+		}
+	}
+
+	if r.lastKey = key; len(key) == 1 {
+		r.lastKey = key[0]
+	}
+
+	return nil
+}
+
+// Scan copies the columns of the current row into the values pointed at by dest, see
+// (*sql.Rows).Scan.
+func (r *PagedRows) Scan(dest ...interface{}) error {
+	if r.rows == nil {
+		return fmt.Errorf("Scan called without calling Next")
+	}
+
+	return r.rows.Scan(dest...)
+}
+
+// Columns returns the column names of the results, they are known after the first call
+// to Next.
+func (r *PagedRows) Columns() []string { return r.cols }
+
+// Err returns the error, if any, that was encountered while iterating.
+func (r *PagedRows) Err() error { return r.err }
+
+// Close stops the iteration, it is not necessary to call it if Next returned false.
+func (r *PagedRows) Close() (err error) {
+	r.done = true
+	if r.rows != nil {
+		err, r.rows = r.rows.Close(), nil
+	}
+
+	return
+}
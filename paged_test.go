@@ -0,0 +1,166 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// pagedAPI serves the ids 1 to n in pages, as a database would for QueryPaged's statements
+func pagedAPI(n int64, pageSize int64) *mockAPI {
+	return &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		var last int64
+		for _, p := range in.Parameters {
+			if aws.StringValue(p.Name) == PageKeyParam {
+				last = aws.Int64Value(p.Value.LongValue)
+			}
+		}
+
+		out := &rdsds.ExecuteStatementOutput{ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("id")}}}
+		for id := last + 1; id <= n && id <= last+pageSize; id++ {
+			out.Records = append(out.Records, []*rdsds.Field{{LongValue: aws.Int64(id)}})
+		}
+
+		return out, nil
+	}}
+}
+
+func TestQueryPaged(t *testing.T) {
+	for _, n := range []int64{0, 5, 6, 7} {
+		m := pagedAPI(n, 3)
+		rows := QueryPaged(context.Background(), mockDB(t, m), "SELECT id FROM foo", "id", 3)
+
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				t.Fatalf("failed to scan: %v", err)
+			}
+
+			ids = append(ids, id)
+		}
+
+		if err := rows.Err(); err != nil {
+			t.Fatalf("failed to iterate: %v", err)
+		}
+
+		if int64(len(ids)) != n || (n > 0 && ids[n-1] != n) {
+			t.Fatalf("expected all %d ids, got: %v", n, ids)
+		}
+
+		if exp := int(n/3 + 1); len(m.executes) != exp {
+			t.Fatalf("expected %d page queries, got: %d", exp, len(m.executes))
+		}
+	}
+
+	m := pagedAPI(2, 3)
+	rows := QueryPaged(context.Background(), mockDB(t, m), "SELECT id FROM foo", "id", 3)
+	rows.Next()
+	if !reflect.DeepEqual(rows.Columns(), []string{"id"}) || !strings.Contains(aws.StringValue(m.executes[0].Sql), "ORDER BY id LIMIT 3") {
+		t.Fatalf("unexpected paged query, got: %v", aws.StringValue(m.executes[0].Sql))
+	}
+
+	rows = QueryPaged(context.Background(), mockDB(t, m), "SELECT id FROM foo", "bar", 3)
+	if rows.Next() || rows.Err() == nil {
+		t.Fatalf("expected error for unknown key column")
+	}
+}
+
+func TestQueryFetch(t *testing.T) {
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		var a, b int64
+		for _, p := range in.Parameters {
+			switch aws.StringValue(p.Name) {
+			case "a":
+				a = aws.Int64Value(p.Value.LongValue)
+			case "b":
+				b = aws.Int64Value(p.Value.LongValue)
+			}
+		}
+
+		// rows (1,1) (1,2) (2,1) (2,2) (3,1) ordered by (a, b), two per page
+		out := &rdsds.ExecuteStatementOutput{ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("a")}, {Name: aws.String("b")}}}
+		for _, r := range [][2]int64{{1, 1}, {1, 2}, {2, 1}, {2, 2}, {3, 1}} {
+			if (r[0] > a || (r[0] == a && r[1] > b)) && len(out.Records) < 2 {
+				out.Records = append(out.Records, []*rdsds.Field{{LongValue: aws.Int64(r[0])}, {LongValue: aws.Int64(r[1])}})
+			}
+		}
+
+		return out, nil
+	}}
+
+	var keys []interface{}
+	rows := QueryFetch(context.Background(), mockDB(t, m), func(lastKey interface{}) (string, []interface{}) {
+		keys = append(keys, lastKey)
+		if lastKey == nil {
+			return "SELECT a, b FROM foo ORDER BY a, b LIMIT 2", nil
+		}
+
+		k := lastKey.([]interface{})
+		return "SELECT a, b FROM foo WHERE (a, b) > (:a, :b) ORDER BY a, b LIMIT 2", []interface{}{sql.Named("a", k[0]), sql.Named("b", k[1])}
+	}, "a", "b")
+
+	var n int
+	for rows.Next() {
+		n++
+	}
+
+	if err := rows.Err(); err != nil || n != 5 {
+		t.Fatalf("expected all 5 rows, got: %d, %v", n, err)
+	}
+
+	exp := []interface{}{nil, []interface{}{int64(1), int64(2)}, []interface{}{int64(2), int64(2)}, []interface{}{int64(3), int64(1)}}
+	if !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("expected the last keys of each page, got: %v", keys)
+	}
+
+	rows = QueryFetch(context.Background(), mockDB(t, m), func(lastKey interface{}) (string, []interface{}) {
+		return "SELECT a, b FROM foo ORDER BY a, b LIMIT 2", nil
+	}, "a", "b")
+
+	for rows.Next() {
+	}
+
+	if err := rows.Err(); err == nil || !strings.Contains(err.Error(), "page after key '[1 2]' did not advance") {
+		t.Fatalf("expected an error for a page that doesn't advance, got: %v", err)
+	}
+}
+
+func TestQueryFetchPageError(t *testing.T) {
+	m := pagedAPI(10, 3)
+	fn := m.ExecuteStatement
+	m.ExecuteStatement = func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if len(in.Parameters) > 0 {
+			return nil, errors.New("boom")
+		}
+
+		return fn(in)
+	}
+
+	rows := QueryFetch(context.Background(), mockDB(t, m), func(lastKey interface{}) (string, []interface{}) {
+		if lastKey == nil {
+			return "SELECT id FROM foo", nil
+		}
+
+		return "SELECT id FROM foo WHERE id > :" + PageKeyParam, []interface{}{sql.Named(PageKeyParam, lastKey)}
+	}, "id")
+
+	var n int
+	for rows.Next() {
+		n++
+	}
+
+	if err := rows.Err(); n != 3 || err == nil || !strings.Contains(err.Error(), "page after key '3'") {
+		t.Fatalf("expected the error of the second page with its key, got: %d, %v", n, err)
+	}
+
+	if rows = QueryFetch(context.Background(), mockDB(t, m), nil); rows.Next() || rows.Err() == nil {
+		t.Fatalf("expected an error without key columns")
+	}
+}
@@ -0,0 +1,87 @@
+package rdsdataapi
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+const (
+	timestampFormat = "2006-01-02 15:04:05.999"
+	dateFormat      = "2006-01-02"
+	timeFormat      = "15:04:05.999"
+)
+
+// Date can be used to pass a time.Time argument as a DATE parameter, sent as
+// 'YYYY-MM-DD' in the time's own location. Any time of day is dropped. A plain
+// time.Time argument is sent as a TIMESTAMP in UTC instead.
+type Date time.Time
+
+// TimeOfDay can be used to pass a time.Time argument as a TIME parameter, sent as
+// 'HH:MM:SS[.FFF]' in the time's own location. The date is dropped.
+type TimeOfDay time.Time
+
+// UUID can be used to pass an UUID argument for a MySQL BINARY(16) column, it is sent as
+// its 16 bytes. Configure 'UUIDColumns' to read such columns back as UUID strings.
+type UUID [16]byte
+
+// Text can be used to pass bytes that hold text, e.g. for a TEXT column, it is sent as a
+// string value. A plain []byte argument is sent as a BLOB instead.
+type Text []byte
+
+// ParseUUID parses the canonical form of an UUID, e.g: '123e4567-e89b-12d3-a456-426614174000'
+func ParseUUID(s string) (u UUID, err error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid UUID '%s', expected the format xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", s)
+	}
+
+	b, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil {
+		return u, fmt.Errorf("invalid UUID '%s': %w", s, err)
+	}
+
+	copy(u[:], b)
+	return
+}
+
+// String returns the canonical form of the UUID
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// CheckNamedValue allows argument types that are specific to this driver to be passed
+// to toParams as-is, all other values are converted by the sql package's default.
+func (c *Conn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch nv.Value.(type) {
+	case Date, TimeOfDay, UUID, Text, Decimal:
+		return nil
+	default:
+		return driver.ErrSkip
+	}
+}
+
+// ParamSet holds arguments that are converted into Data API parameters just once, such that
+// they can be re-used for many statements without the cost of converting them each time.
+type ParamSet struct{ params []*rdsds.SqlParameter }
+
+// NewParams converts the named arguments into a parameter set, see WithParams
+func NewParams(args ...sql.NamedArg) (ps ParamSet, err error) {
+	nvs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nvs[i] = driver.NamedValue{Name: arg.Name, Ordinal: i + 1, Value: arg.Value}
+		if err = (&Conn{}).CheckNamedValue(&nvs[i]); err == driver.ErrSkip {
+			if nvs[i].Value, err = driver.DefaultParameterConverter.ConvertValue(arg.Value); err != nil {
+				return ps, fmt.Errorf("failed to convert argument '%s': %w", arg.Name, err)
+			}
+		}
+	}
+
+	ps.params, err = (&Conn{}).toParams(nvs)
+	return
+}
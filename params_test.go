@@ -0,0 +1,189 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestTimeParamTypeHints(t *testing.T) {
+	m := &mockAPI{}
+	db := mockDB(t, m)
+
+	tm := time.Date(2020, 2, 15, 13, 4, 5, 120000000, time.UTC)
+	if _, err := db.Exec("INSERT INTO foo VALUES (:ts, :d, :t)",
+		sql.Named("ts", tm), sql.Named("d", Date(tm)), sql.Named("t", TimeOfDay(tm))); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	for i, exp := range []struct{ hint, val string }{
+		{rdsds.TypeHintTimestamp, "2020-02-15 13:04:05.12"},
+		{rdsds.TypeHintDate, "2020-02-15"},
+		{rdsds.TypeHintTime, "13:04:05.12"},
+	} {
+		p := m.executes[0].Parameters[i]
+		if aws.StringValue(p.TypeHint) != exp.hint || aws.StringValue(p.Value.StringValue) != exp.val {
+			t.Fatalf("%d: expected hint '%s' and value '%s', got: %v", i, exp.hint, exp.val, p)
+		}
+	}
+}
+
+func TestUUIDParamAndColumn(t *testing.T) {
+	const id = "123e4567-e89b-12d3-a456-426614174000"
+	u, err := ParseUUID(id)
+	if err != nil || u.String() != id {
+		t.Fatalf("expected UUID to round-trip, got: %v (%v)", u, err)
+	}
+
+	for _, s := range []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400z"} {
+		if _, err = ParseUUID(s); err == nil {
+			t.Fatalf("expected error for invalid UUID '%s'", s)
+		}
+	}
+
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("id")}, {Name: aws.String("other")}},
+			Records:        [][]*rdsds.Field{{{BlobValue: u[:]}, {BlobValue: u[:]}}},
+		}, nil
+	}}
+
+	cfg := testCfg
+	cfg.UUIDColumns = []string{"id"}
+	db := sql.OpenDB(newConnector(cfg, m))
+	if _, err = db.Exec("INSERT INTO foo VALUES (:id)", sql.Named("id", u)); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	if b := m.executes[0].Parameters[0].Value.BlobValue; len(b) != 16 || b[0] != 0x12 || b[15] != 0x00 {
+		t.Fatalf("expected UUID to be sent as 16 bytes, got: %x", b)
+	}
+
+	var (
+		s     string
+		other []byte
+	)
+
+	if err = db.QueryRow("SELECT id, other FROM foo").Scan(&s, &other); err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+
+	if s != id || len(other) != 16 {
+		t.Fatalf("expected only the configured column to be formatted, got: '%s' and %x", s, other)
+	}
+}
+
+func TestTextParam(t *testing.T) {
+	m := &mockAPI{}
+	db := mockDB(t, m)
+	if _, err := db.Exec("INSERT INTO foo VALUES (:t, :b)", sql.Named("t", Text("héllo")), sql.Named("b", []byte("héllo"))); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if ps := m.executes[0].Parameters; aws.StringValue(ps[0].Value.StringValue) != "héllo" || string(ps[1].Value.BlobValue) != "héllo" {
+		t.Fatalf("expected text as a string and bytes as a blob, got: %v", ps)
+	}
+
+	if _, err := db.Exec("INSERT INTO foo VALUES (:t)", sql.Named("t", Text{0xff})); err == nil {
+		t.Fatalf("expected error for invalid UTF-8 text")
+	}
+}
+
+func TestNullParams(t *testing.T) {
+	tm := time.Date(2020, 2, 15, 13, 4, 5, 0, time.UTC)
+	for i, c := range []struct {
+		arg interface{}
+		exp rdsds.Field
+	}{
+		{sql.NullString{String: "foo", Valid: true}, rdsds.Field{StringValue: aws.String("foo")}},
+		{sql.NullInt64{Int64: 64, Valid: true}, rdsds.Field{LongValue: aws.Int64(64)}},
+		{sql.NullInt32{Int32: 32, Valid: true}, rdsds.Field{LongValue: aws.Int64(32)}},
+		{sql.NullFloat64{Float64: 1.5, Valid: true}, rdsds.Field{DoubleValue: aws.Float64(1.5)}},
+		{sql.NullBool{Bool: true, Valid: true}, rdsds.Field{BooleanValue: aws.Bool(true)}},
+		{sql.NullTime{Time: tm, Valid: true}, rdsds.Field{StringValue: aws.String("2020-02-15 13:04:05")}},
+		{sql.NullString{}, rdsds.Field{IsNull: aws.Bool(true)}},
+		{sql.NullInt64{}, rdsds.Field{IsNull: aws.Bool(true)}},
+		{sql.NullInt32{}, rdsds.Field{IsNull: aws.Bool(true)}},
+		{sql.NullFloat64{}, rdsds.Field{IsNull: aws.Bool(true)}},
+		{sql.NullBool{}, rdsds.Field{IsNull: aws.Bool(true)}},
+		{sql.NullTime{}, rdsds.Field{IsNull: aws.Bool(true)}},
+		{nil, rdsds.Field{IsNull: aws.Bool(true)}},
+	} {
+		m := &mockAPI{}
+		if _, err := mockDB(t, m).Exec("INSERT INTO foo VALUES (:v)", sql.Named("v", c.arg)); err != nil {
+			t.Fatalf("%d: failed to exec with %T: %v", i, c.arg, err)
+		}
+
+		if act := m.executes[0].Parameters[0].Value; act.String() != c.exp.String() {
+			t.Fatalf("%d: expected %T to be sent as %v, got: %v", i, c.arg, c.exp, act)
+		}
+	}
+}
+
+func TestParamSet(t *testing.T) {
+	ps, err := NewParams(sql.Named("id", 1), sql.Named("d", Date(time.Date(2020, 2, 15, 0, 0, 0, 0, time.UTC))))
+	if err != nil {
+		t.Fatalf("failed to create params: %v", err)
+	}
+
+	if _, err = NewParams(sql.Named("x", struct{}{})); err == nil {
+		t.Fatalf("expected error for unsupported argument")
+	}
+
+	m := &mockAPI{}
+	db := mockDB(t, m)
+	ctx := WithParams(context.Background(), ps)
+	if _, err = db.ExecContext(ctx, "DELETE FROM foo WHERE id = :id AND d = :d"); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if _, err = db.ExecContext(ctx, "DELETE FROM foo WHERE id = :id", sql.Named("id", 2)); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	p := m.executes[0].Parameters
+	if len(p) != 2 || aws.Int64Value(p[0].Value.LongValue) != 1 || aws.StringValue(p[1].TypeHint) != rdsds.TypeHintDate {
+		t.Fatalf("expected parameters of the set, got: %v", p)
+	}
+
+	if p = m.executes[1].Parameters; len(p) != 1 || aws.Int64Value(p[0].Value.LongValue) != 2 {
+		t.Fatalf("expected the statement's own arguments to take precedence, got: %v", p)
+	}
+}
+
+func BenchmarkParams(b *testing.B) {
+	args := []interface{}{sql.Named("id", 1), sql.Named("name", "foo"), sql.Named("ts", time.Now()), sql.Named("ok", true)}
+	named := make([]sql.NamedArg, len(args))
+	for i, arg := range args {
+		named[i] = arg.(sql.NamedArg)
+	}
+
+	b.Run("args", func(b *testing.B) {
+		db, ctx := mockDB(b, &mockAPI{}), context.Background()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := db.ExecContext(ctx, "UPDATE foo SET name = :name, ts = :ts, ok = :ok WHERE id = :id", args...); err != nil {
+				b.Fatalf("failed to exec: %v", err)
+			}
+		}
+	})
+
+	b.Run("param set", func(b *testing.B) {
+		ps, err := NewParams(named...)
+		if err != nil {
+			b.Fatalf("failed to create params: %v", err)
+		}
+
+		db, ctx := mockDB(b, &mockAPI{}), WithParams(context.Background(), ps)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := db.ExecContext(ctx, "UPDATE foo SET name = :name, ts = :ts, ok = :ok WHERE id = :id"); err != nil {
+				b.Fatalf("failed to exec: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package rdsdataapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteIdentifier quotes a table or column name for use in a statement of the given engine:
+// with backticks for EngineMySQL and double quotes for EnginePostgres. The Data API can't
+// bind identifiers as parameters, so this is for building statements with a dynamic table
+// or column name. Names that contain the quote character, a null byte or are empty are
+// rejected instead of escaped. It is for identifiers only, values must always be passed as
+// arguments.
+func QuoteIdentifier(engine, name string) (string, error) {
+	var quote string
+	switch engine {
+	case EngineMySQL:
+		quote = "`"
+	case EnginePostgres:
+		quote = `"`
+	default:
+		return "", fmt.Errorf("engine must be '%s' or '%s', got: '%s'", EngineMySQL, EnginePostgres, engine)
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("identifier must not be empty")
+	}
+
+	if strings.Contains(name, quote) || strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("identifier '%s' must not contain %s or a null byte", strings.Replace(name, "\x00", `\0`, -1), quote)
+	}
+
+	return quote + name + quote, nil
+}
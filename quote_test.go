@@ -0,0 +1,24 @@
+package rdsdataapi
+
+import "testing"
+
+func TestQuoteIdentifier(t *testing.T) {
+	for i, c := range []struct {
+		engine, name, exp string
+	}{
+		{EngineMySQL, "users", "`users`"},
+		{EngineMySQL, `my "table"`, "`my \"table\"`"},
+		{EnginePostgres, "Users", `"Users"`},
+		{EnginePostgres, "it's", `"it's"`},
+		{EngineMySQL, "a`b", ""},
+		{EnginePostgres, `a"b`, ""},
+		{EnginePostgres, "a\x00b", ""},
+		{EngineMySQL, "", ""},
+		{"oracle", "users", ""},
+	} {
+		act, err := QuoteIdentifier(c.engine, c.name)
+		if (err != nil) != (c.exp == "") || act != c.exp {
+			t.Fatalf("%d: expected '%s', got: '%s' (%v)", i, c.exp, act, err)
+		}
+	}
+}
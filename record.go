@@ -0,0 +1,168 @@
+package rdsdataapi
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// DecodeRecord decodes a record of the Data API's output into the struct pointed to by dest,
+// for results that were not read through the driver, e.g. from calling the SDK directly. The
+// fields are decoded as the driver does with its default configuration and mapped onto the
+// struct as with ScanStruct, using the column labels (or names) from meta. Numbers are
+// converted to the field's type if they fit, strings are parsed into numeric, boolean and
+// time.Time fields. NULL can only be decoded into a pointer, slice, interface, time.Time or
+// sql.Scanner field.
+func DecodeRecord(meta []*rdsds.ColumnMetadata, record []*rdsds.Field, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a non-nil pointer to a struct, got: %T", dest)
+	}
+
+	if len(record) != len(meta) {
+		return fmt.Errorf("record has %d fields but the metadata describes %d columns", len(record), len(meta))
+	}
+
+	fields := map[string][]int{}
+	structFields(rv.Elem().Type(), nil, map[reflect.Type]bool{}, fields)
+
+	r := &Rows{output: &rdsds.ExecuteStatementOutput{ColumnMetadata: meta}}
+	cols, paths := r.Columns(), make([][]int, len(meta))
+	for i, col := range cols {
+		var ok bool
+		if paths[i], ok = fields[strings.ToLower(col)]; !ok {
+			return fmt.Errorf("no field in %T to decode column '%s' into", dest, col)
+		}
+	}
+
+	for i, col := range cols {
+		f := fieldByPath(rv.Elem(), paths[i])
+		v, err := r.decodeColumn(i, record[i])
+		if err != nil {
+			return fmt.Errorf("failed to decode column '%s': %w", col, err)
+		}
+
+		if err = assignValue(f, v); err != nil {
+			return fmt.Errorf("failed to decode column '%s': %w", col, err)
+		}
+	}
+
+	return nil
+}
+
+// assignValue sets the settable field f to the decoded value v, converting it if necessary
+func assignValue(f reflect.Value, v interface{}) error {
+	if f.CanAddr() && f.Addr().Type().Implements(scannerType) {
+		return f.Addr().Interface().(sql.Scanner).Scan(v)
+	}
+
+	if f.Type() == timeType {
+		return (*timeScanner)(f.Addr().Interface().(*time.Time)).Scan(v)
+	}
+
+	if v == nil {
+		switch f.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		default:
+			return fmt.Errorf("value is NULL, which can't be stored in a field of type %s: use a pointer or sql.Null* type", f.Type())
+		}
+	}
+
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(f.Type().Elem())
+		if err := assignValue(p.Elem(), v); err != nil {
+			return err
+		}
+
+		f.Set(p)
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(f.Type()) {
+		f.Set(rv)
+		return nil
+	}
+
+	if s, ok := v.(string); ok {
+		return assignString(f, s)
+	}
+
+	switch {
+	case isIntKind(f.Kind()) && isIntKind(rv.Kind()):
+		if f.OverflowInt(rv.Int()) {
+			return fmt.Errorf("value %d overflows a field of type %s", rv.Int(), f.Type())
+		}
+
+		f.SetInt(rv.Int())
+	case isUintKind(f.Kind()) && isIntKind(rv.Kind()):
+		if rv.Int() < 0 || f.OverflowUint(uint64(rv.Int())) {
+			return fmt.Errorf("value %d overflows a field of type %s", rv.Int(), f.Type())
+		}
+
+		f.SetUint(uint64(rv.Int()))
+	case isFloatKind(f.Kind()) && (isFloatKind(rv.Kind()) || isIntKind(rv.Kind())):
+		f.Set(rv.Convert(f.Type()))
+	case f.Kind() == reflect.String && rv.Type() == reflect.TypeOf([]byte(nil)):
+		f.SetString(string(rv.Bytes()))
+	case rv.Type().ConvertibleTo(f.Type()) && rv.Kind() == f.Kind():
+		f.Set(rv.Convert(f.Type()))
+	default:
+		return fmt.Errorf("value of type %T can't be stored in a field of type %s", v, f.Type())
+	}
+
+	return nil
+}
+
+// assignString parses s into the field f, e.g. a DECIMAL that the Data API returns as string
+func assignString(f reflect.Value, s string) error {
+	switch k := f.Kind(); {
+	case k == reflect.String:
+		f.SetString(s)
+	case k == reflect.Slice && f.Type().Elem().Kind() == reflect.Uint8:
+		f.SetBytes([]byte(s))
+	case isIntKind(k):
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("failed to parse '%s' into a field of type %s: %w", s, f.Type(), err)
+		}
+
+		f.SetInt(n)
+	case isUintKind(k):
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("failed to parse '%s' into a field of type %s: %w", s, f.Type(), err)
+		}
+
+		f.SetUint(n)
+	case isFloatKind(k):
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("failed to parse '%s' into a field of type %s: %w", s, f.Type(), err)
+		}
+
+		f.SetFloat(n)
+	case k == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("failed to parse '%s' into a field of type %s: %w", s, f.Type(), err)
+		}
+
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("value of type string can't be stored in a field of type %s", f.Type())
+	}
+
+	return nil
+}
+
+func isIntKind(k reflect.Kind) bool   { return k >= reflect.Int && k <= reflect.Int64 }
+func isUintKind(k reflect.Kind) bool  { return k >= reflect.Uint && k <= reflect.Uintptr }
+func isFloatKind(k reflect.Kind) bool { return k == reflect.Float32 || k == reflect.Float64 }
@@ -0,0 +1,83 @@
+package rdsdataapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestDecodeRecord(t *testing.T) {
+	meta := []*rdsds.ColumnMetadata{
+		{Name: aws.String("id"), TypeName: aws.String("INT")},
+		{Name: aws.String("name"), TypeName: aws.String("VARCHAR")},
+		{Name: aws.String("price"), TypeName: aws.String("DECIMAL")},
+		{Name: aws.String("created"), TypeName: aws.String("TIMESTAMP")},
+		{Name: aws.String("active"), TypeName: aws.String("TINYINT"), Precision: aws.Int64(1)},
+		{Name: aws.String("note"), TypeName: aws.String("VARCHAR")},
+		{Name: aws.String("c"), Label: aws.String("nick"), TypeName: aws.String("VARCHAR")},
+		{Name: aws.String("doc"), TypeName: aws.String("JSON")},
+	}
+
+	record := []*rdsds.Field{
+		{LongValue: aws.Int64(7)},
+		{StringValue: aws.String("foo")},
+		{StringValue: aws.String("1.50")},
+		{StringValue: aws.String("2020-01-02 03:04:05")},
+		{LongValue: aws.Int64(1)},
+		{IsNull: aws.Bool(true)},
+		{IsNull: aws.Bool(true)},
+		{StringValue: aws.String(`{"a":1}`)},
+	}
+
+	var dest struct {
+		ID      int32
+		Name    string
+		Price   float64
+		Created time.Time
+		Active  bool
+		Note    *string
+		Nick    sql.NullString
+		Doc     json.RawMessage
+	}
+
+	if err := DecodeRecord(meta, record, &dest); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if dest.ID != 7 || dest.Name != "foo" || dest.Price != 1.5 || !dest.Created.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) ||
+		!dest.Active || dest.Note != nil || dest.Nick.Valid || string(dest.Doc) != `{"a":1}` {
+		t.Fatalf("unexpected decoded record, got: %+v", dest)
+	}
+
+	record[5] = &rdsds.Field{StringValue: aws.String("bar")}
+	if err := DecodeRecord(meta, record, &dest); err != nil || dest.Note == nil || *dest.Note != "bar" {
+		t.Fatalf("expected the pointer field to be set, got: %v (%v)", dest.Note, err)
+	}
+}
+
+func TestDecodeRecordErrors(t *testing.T) {
+	meta := []*rdsds.ColumnMetadata{{Name: aws.String("n"), TypeName: aws.String("BIGINT")}}
+	for i, c := range []struct {
+		record []*rdsds.Field
+		dest   interface{}
+		exp    string
+	}{
+		{[]*rdsds.Field{{LongValue: aws.Int64(1)}}, struct{ N int }{}, "must be a non-nil pointer to a struct"},
+		{nil, &struct{ N int }{}, "record has 0 fields but the metadata describes 1 columns"},
+		{[]*rdsds.Field{{LongValue: aws.Int64(1)}}, &struct{ M int }{}, "no field in *struct { M int } to decode column 'n' into"},
+		{[]*rdsds.Field{{IsNull: aws.Bool(true)}}, &struct{ N int }{}, "column 'n': value is NULL"},
+		{[]*rdsds.Field{{LongValue: aws.Int64(300)}}, &struct{ N int8 }{}, "value 300 overflows a field of type int8"},
+		{[]*rdsds.Field{{LongValue: aws.Int64(-1)}}, &struct{ N uint }{}, "value -1 overflows a field of type uint"},
+		{[]*rdsds.Field{{StringValue: aws.String("abc")}}, &struct{ N int }{}, "failed to parse 'abc' into a field of type int"},
+		{[]*rdsds.Field{{BooleanValue: aws.Bool(true)}}, &struct{ N string }{}, "value of type bool can't be stored in a field of type string"},
+	} {
+		if err := DecodeRecord(meta, c.record, c.dest); err == nil || !strings.Contains(err.Error(), c.exp) {
+			t.Fatalf("%d: expected error containing '%s', got: %v", i, c.exp, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// describeParams describes each parameter for logs and errors as ':name=value'. Unless
+// values are shown for the statement (see RedactParams and WithParamValues) the value is
+// replaced with a placeholder of its type, and its length for strings and blobs.
+func (c *Conn) describeParams(ctx context.Context, params []*rdsds.SqlParameter) []string {
+	redact := c.cfg.redactParams() && !paramValuesFromContext(ctx)
+	ds := make([]string, len(params))
+	for i, p := range params {
+		ds[i] = ":" + aws.StringValue(p.Name) + "=" + describeField(p.Value, redact)
+	}
+
+	return ds
+}
+
+// describeField returns the value of the field, or a placeholder for it if redact is set.
+// NULL is never redacted, blobs always are as their bytes are not readable.
+func describeField(f *rdsds.Field, redact bool) string {
+	switch {
+	case f == nil || aws.BoolValue(f.IsNull):
+		return "NULL"
+	case f.BlobValue != nil:
+		return fmt.Sprintf("<blob(%d)>", len(f.BlobValue))
+	case redact && f.StringValue != nil:
+		return fmt.Sprintf("<string(%d)>", len([]rune(*f.StringValue)))
+	case f.StringValue != nil:
+		return fmt.Sprintf("%q", *f.StringValue)
+	case redact && f.LongValue != nil:
+		return "<long>"
+	case f.LongValue != nil:
+		return fmt.Sprint(*f.LongValue)
+	case redact && f.DoubleValue != nil:
+		return "<double>"
+	case f.DoubleValue != nil:
+		return fmt.Sprint(*f.DoubleValue)
+	case redact && f.BooleanValue != nil:
+		return "<boolean>"
+	case f.BooleanValue != nil:
+		return fmt.Sprint(*f.BooleanValue)
+	default:
+		return "<array>"
+	}
+}
+
+// paramsError returns a description of the parameters for the error of a failed statement,
+// it is empty if the statement had no parameters.
+func (c *Conn) paramsError(ctx context.Context, params []*rdsds.SqlParameter) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	return " with parameters [" + strings.Join(c.describeParams(ctx, params), ", ") + "]"
+}
+
+// maskedValue replaces the parts of a configuration value that are masked by Conn.Config
+const maskedValue = "***"
+
+// maskSecretARN masks the name of the secret in a secret ARN, keeping the partition, region
+// and account it is in, e.g: 'arn:aws:secretsmanager:eu-west-1:123456789012:secret:***'. A
+// secret that is configured by name, instead of ARN, is masked completely.
+func maskSecretARN(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	a, err := arn.Parse(s)
+	if err != nil {
+		return maskedValue
+	}
+
+	a.Resource = "secret:" + maskedValue
+	return a.String()
+}
+
+// maskURLPassword masks the password of the user info in a url, if it has one. A url that
+// can't be parsed is masked completely, as it may hold a password all the same.
+func maskURLPassword(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return maskedValue
+	}
+
+	if u.User == nil {
+		return s
+	}
+
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+
+	// url.URL escapes the mask, so it is put in place of a placeholder that needs no escaping.
+	// Colons in the user name are escaped, so only the password is followed by the '@'.
+	const placeholder = "masked"
+	u.User = url.UserPassword(u.User.Username(), placeholder)
+	return strings.Replace(u.String(), ":"+placeholder+"@", ":"+maskedValue+"@", 1)
+}
@@ -0,0 +1,196 @@
+package rdsdataapi
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+const (
+	// JitterFull waits a random duration between zero and the backoff delay
+	JitterFull = "full"
+
+	// JitterNone always waits the full backoff delay
+	JitterNone = "none"
+)
+
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// isRetryable returns whether err is a transport level or throttling error. These errors
+// mean the statement was probably not executed, but this is not guaranteed: a transport
+// error can also occur after the database received and applied the statement.
+func isRetryable(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	if aerr.Code() == request.CanceledErrorCode {
+		return false
+	}
+
+	return aerr.Code() == rdsds.ErrCodeServiceUnavailableError ||
+		request.IsErrorThrottle(aerr) ||
+		request.IsErrorRetryable(aerr)
+}
+
+// transientAuthRetries is the nr of times an error for which isTransientAuth holds is
+// retried, independent of the configured MaxRetries.
+const transientAuthRetries = 2
+
+// isTransientAuth returns whether err is an access or secret error that is known to resolve
+// on retry, as happens for the first calls after an Aurora Serverless cluster resumed. The
+// Data API has no dedicated error codes for these so, as a heuristic, the message must
+// mention the secret and a failure to fetch it or a timeout. Errors that say the caller is
+// not authorized are never transient.
+func isTransientAuth(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+
+	switch aerr.Code() {
+	case errCodeAccessDenied, rdsds.ErrCodeForbiddenException, rdsds.ErrCodeBadRequestException:
+	default:
+		return false
+	}
+
+	msg := strings.ToLower(aerr.Message())
+	if isNotAuthorized(msg) || !strings.Contains(msg, "secret") {
+		return false
+	}
+
+	for _, s := range []string{"unable to fetch", "error fetching", "unable to retrieve", "timed out", "timeout", "temporar", "try again"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canRetry returns whether a statement of the given kind may be retried at all. Within a
+// transaction a failed write might have been (partially) applied, replaying it could then
+// apply it twice (e.g. duplicate an INSERT). So by default the error is returned, allowing
+// the caller to roll back. Reads are always safe to replay, for writes this can be
+// overwritten per statement with WithRetryInTx.
+func (c *Conn) canRetry(ctx context.Context, kind StatementKind) bool {
+	return c.transactionID == "" || kind == StatementSelect || retryInTxFromContext(ctx)
+}
+
+// retryer retries retryable errors with an exponential backoff. It is shared by all
+// connections of a connector. A nil retryer calls the function just once.
+type retryer struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	jitter     string
+	capacity   int      // nr of retries for capacity errors, see isCapacityError
+	substrings []string // the message substrings of capacity errors
+	metrics    MetricsCollector
+	counters   *counters
+
+	clock clock // waits for the delay, see Connector.setClock
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newRetryer creates a retryer from the retry options of the config
+func newRetryer(cfg Config) *retryer {
+	r := &retryer{
+		maxRetries: cfg.MaxRetries,
+		baseDelay:  cfg.RetryBaseDelay,
+		maxDelay:   cfg.RetryMaxDelay,
+		jitter:     cfg.RetryJitter,
+		capacity:   cfg.CapacityRetries,
+		substrings: cfg.CapacityErrorSubstrings,
+		metrics:    cfg.Metrics,
+		clock:      realClock{},
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	if r.baseDelay == 0 {
+		r.baseDelay = defaultRetryBaseDelay
+	}
+
+	if r.maxDelay == 0 {
+		r.maxDelay = defaultRetryMaxDelay
+	}
+
+	if r.jitter == "" {
+		r.jitter = JitterFull
+	}
+
+	return r
+}
+
+// delay returns how long to wait before the retry following the given (zero based)
+// attempt: the base delay doubled for every attempt, capped at the max delay.
+func (r *retryer) delay(attempt int) time.Duration {
+	d := r.maxDelay
+	if attempt < 32 && r.baseDelay<<uint(attempt) < r.maxDelay {
+		d = r.baseDelay << uint(attempt)
+	}
+
+	if r.jitter != JitterFull || d <= 0 {
+		return d
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Duration(r.rand.Int63n(int64(d) + 1))
+}
+
+// do calls fn until it succeeds, returns an error that is not retryable or the max nr
+// of retries is reached. Transient auth errors are retried a few times even if no retries
+// are configured, capacity errors are retried up to the configured CapacityRetries. When
+// the context is done while waiting for a retry it returns the last error that fn returned.
+func (r *retryer) do(ctx context.Context, fn func() error) (err error) {
+	if r == nil {
+		return fn()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		if !(attempt < r.maxRetries && isRetryable(err)) &&
+			!(attempt < transientAuthRetries && isTransientAuth(err)) &&
+			!(attempt < r.capacity && isCapacityError(err, r.substrings)) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-r.clock.After(r.delay(attempt)):
+		}
+
+		r.counters.inc(statRetries)
+		if r.metrics != nil {
+			r.metrics.IncRetry()
+		}
+	}
+}
+
+// stmtRetryer returns the retryer for statements of the given kind executed with ctx, or
+// nil if the statement must not be retried.
+func (c *Conn) stmtRetryer(ctx context.Context, kind StatementKind) *retryer {
+	if !c.canRetry(ctx, kind) {
+		return nil
+	}
+
+	return c.retryer
+}
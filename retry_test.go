@@ -0,0 +1,128 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// failingAPI returns a mock that fails the first n executions with an error of the given code
+func failingAPI(n int, code string) *mockAPI {
+	return &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if n > 0 {
+			n--
+			return nil, awserr.New(code, "failed", nil)
+		}
+
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}}
+}
+
+// instantRetryer returns a retryer that doesn't wait in between retries
+func instantRetryer(max int) *retryer {
+	r := newRetryer(Config{MaxRetries: max})
+	r.clock = newFakeClock(true)
+	return r
+}
+
+func TestRetryIdempotency(t *testing.T) {
+	for i, c := range []struct {
+		code      string
+		query     string
+		inTx      bool
+		retryInTx bool
+		expErr    bool
+		expCalls  int
+	}{
+		{"ThrottlingException", "INSERT INTO foo VALUES ()", false, false, false, 3},
+		{rdsds.ErrCodeServiceUnavailableError, "INSERT INTO foo VALUES ()", false, false, false, 3},
+		{rdsds.ErrCodeBadRequestException, "INSERT INTO foo VALUES ()", false, false, true, 1},
+		{"ThrottlingException", "INSERT INTO foo VALUES ()", true, false, true, 1},
+		{"ThrottlingException", "INSERT INTO foo VALUES ()", true, true, false, 3},
+		{"ThrottlingException", "SELECT * FROM foo", true, false, false, 3},
+	} {
+		m := failingAPI(2, c.code)
+		conn := mockConn(t, m)
+		conn.retryer = instantRetryer(2)
+
+		ctx := context.Background()
+		if c.inTx {
+			if _, err := conn.BeginTx(ctx, driver.TxOptions{}); err != nil {
+				t.Fatalf("%d: failed to begin: %v", i, err)
+			}
+		}
+
+		if c.retryInTx {
+			ctx = WithRetryInTx(ctx)
+		}
+
+		_, err := conn.ExecContext(ctx, c.query, nil)
+		if (err != nil) != c.expErr {
+			t.Fatalf("%d: expected error to be %v, got: %v", i, c.expErr, err)
+		}
+
+		if len(m.executes) != c.expCalls {
+			t.Fatalf("%d: expected %d calls, got: %d", i, c.expCalls, len(m.executes))
+		}
+	}
+}
+
+// backoffSchedule returns the delays the retryer waits when all attempts are throttled
+func backoffSchedule(t *testing.T, r *retryer) (waits []time.Duration) {
+	clk := newFakeClock(true)
+	r.clock = clk
+
+	var calls int
+	if err := r.do(context.Background(), func() error {
+		calls++
+		return awserr.New("ThrottlingException", "slow down", nil)
+	}); err == nil || calls != r.maxRetries+1 {
+		t.Fatalf("expected last error after %d calls, got: %v after %d", r.maxRetries+1, err, calls)
+	}
+
+	return clk.Waits()
+}
+
+func TestRetryerBackoffSchedule(t *testing.T) {
+	cfg := Config{MaxRetries: 5, RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: 500 * time.Millisecond, RetryJitter: JitterNone}
+	max := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}
+
+	if waits := backoffSchedule(t, newRetryer(cfg)); !reflect.DeepEqual(waits, max) {
+		t.Fatalf("unexpected backoff schedule without jitter, got: %v", waits)
+	}
+
+	cfg.RetryJitter = JitterFull
+	r1, r2 := newRetryer(cfg), newRetryer(cfg)
+	r1.rand, r2.rand = rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+
+	waits := backoffSchedule(t, r1)
+	if !reflect.DeepEqual(waits, backoffSchedule(t, r2)) {
+		t.Fatalf("expected the same schedule for the same random source")
+	}
+
+	for i, w := range waits {
+		if w < 0 || w > max[i] {
+			t.Fatalf("wait %d with full jitter out of bounds, got: %v", i, w)
+		}
+	}
+}
+
+func TestRetryerStopsOnDoneContext(t *testing.T) {
+	r := newRetryer(Config{MaxRetries: 5})
+	r.clock = newFakeClock(false) // never advances
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exp := awserr.New("ThrottlingException", "slow down", nil)
+
+	var calls int
+	err := r.do(ctx, func() error { calls++; cancel(); return exp })
+	if err != exp || calls != 1 {
+		t.Fatalf("expected last error after a single call, got: %v after %d", err, calls)
+	}
+}
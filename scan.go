@@ -0,0 +1,258 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// ScanStruct scans the current row into the struct pointed to by dest. Columns are mapped
+// onto fields by their `db:"..."` tag, or by case-insensitive field name if the field
+// has no tag. Fields of (embedded) struct types are searched as well, unless they implement
+// sql.Scanner. Nil pointers to embedded structs are allocated when a column maps onto one
+// of their fields. Fields tagged with `db:"-"` are ignored. It returns an error if a column
+// cannot be mapped onto a field. As with rows.Scan, rows.Next must be called first.
+func ScanStruct(rows *sql.Rows, dest interface{}) (err error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a non-nil pointer to a struct, got: %T", dest)
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	fields := map[string][]int{}
+	structFields(rv.Elem().Type(), nil, map[reflect.Type]bool{}, fields)
+
+	paths := make([][]int, len(cols))
+	for i, col := range cols {
+		var ok bool
+		if paths[i], ok = fields[strings.ToLower(col)]; !ok {
+			return fmt.Errorf("no field in %T to scan column '%s' into", dest, col)
+		}
+	}
+
+	ptrs := make([]interface{}, len(cols))
+	for i, path := range paths {
+		ptrs[i] = fieldByPath(rv.Elem(), path).Addr().Interface()
+	}
+
+	return rows.Scan(ptrs...)
+}
+
+// structFields collects the index paths of the settable fields of struct type t, below the
+// provided path, by their lower-cased column name. Fields of outer structs take precedence
+// over (equally named) fields of nested structs. Each struct type is searched once, such
+// that a struct embedding a pointer to its own type doesn't recurse forever. Unexported
+// embedded pointers are skipped, as they can't be allocated.
+func structFields(t reflect.Type, path []int, seen map[reflect.Type]bool, fields map[string][]int) {
+	seen[t] = true
+
+	var nested []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue // unexported
+		}
+
+		name, _ := dbTag(sf)
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Ptr && isNestedStruct(sf.Type.Elem()) {
+			if sf.PkgPath == "" {
+				nested = append(nested, sf)
+			}
+
+			continue
+		}
+
+		if name == "" && isNestedStruct(sf.Type) {
+			nested = append(nested, sf)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue // unexported embedded non-struct
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = append(append([]int{}, path...), i)
+		}
+	}
+
+	for _, sf := range nested {
+		typ := sf.Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		if !seen[typ] {
+			structFields(typ, append(append([]int{}, path...), sf.Index...), seen, fields)
+		}
+	}
+}
+
+// fieldByPath returns the field of struct v at the index path from structFields, allocating
+// nil pointers to embedded structs along the way.
+func fieldByPath(v reflect.Value, path []int) reflect.Value {
+	for _, i := range path {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(i)
+	}
+
+	return v
+}
+
+// dbTag returns the column name and whether the omitempty option is set from the `db:"..."`
+// tag of the struct field.
+func dbTag(sf reflect.StructField) (name string, omitempty bool) {
+	parts := strings.Split(sf.Tag.Get("db"), ",")
+	for _, opt := range parts[1:] {
+		omitempty = omitempty || opt == "omitempty"
+	}
+
+	return parts[0], omitempty
+}
+
+// isNestedStruct returns whether fields of type typ are searched for fields of their own,
+// instead of being a single value such as time.Time or a sql.Scanner/driver.Valuer.
+func isNestedStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeType &&
+		!reflect.PtrTo(typ).Implements(scannerType) && !typ.Implements(valuerType)
+}
+
+// NamedArgsFromStruct returns a sql.Named argument for each field of the struct v (or a
+// pointer to it), named by its `db:"..."` tag or else its field name. Fields of (embedded)
+// struct types are included as well, as with ScanStruct. Fields tagged with `db:"-"` are
+// skipped, as are fields tagged with the omitempty option (`db:"name,omitempty"`) that have
+// their zero value. Nil pointer fields are passed as NULL.
+func NamedArgsFromStruct(v interface{}) (args []interface{}, err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("argument must be a struct or a non-nil pointer to one, got: %T", v)
+	}
+
+	structArgs(rv, map[string]bool{}, &args)
+	return args, nil
+}
+
+// structArgs appends the named arguments for the fields of struct v whose names haven't
+// been seen yet. Fields of outer structs take precedence over those of nested structs.
+func structArgs(v reflect.Value, seen map[string]bool, args *[]interface{}) {
+	var nested []reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		sf, f := v.Type().Field(i), v.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue // unexported
+		}
+
+		name, omitempty := dbTag(sf)
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Ptr && isNestedStruct(sf.Type.Elem()) {
+			if !f.IsNil() {
+				nested = append(nested, f.Elem())
+			}
+
+			continue
+		}
+
+		if name == "" && isNestedStruct(sf.Type) {
+			nested = append(nested, f)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue // unexported embedded non-struct
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		if seen[strings.ToLower(name)] || (omitempty && f.IsZero()) {
+			continue
+		}
+
+		seen[strings.ToLower(name)] = true
+		*args = append(*args, sql.Named(name, f.Interface()))
+	}
+
+	for _, f := range nested {
+		structArgs(f, seen, args)
+	}
+}
+
+// Queryer is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// QueryMaps executes the query and returns each row as a map from column name to value.
+// Values have the type they were decoded into by the driver, NULL values are nil. If
+// multiple columns have the same name the last one wins, consider the 'DedupColumns'
+// option to prevent that.
+func QueryMaps(ctx context.Context, db Queryer, query string, args ...interface{}) (ms []map[string]interface{}, err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		m := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			m[col] = vals[i]
+		}
+
+		ms = append(ms, m)
+	}
+
+	return ms, rows.Err()
+}
@@ -0,0 +1,178 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+type testAudit struct {
+	CreatedBy string `db:"created_by"`
+	ID        string // shadowed by the outer struct's ID
+}
+
+// NoteFields is exported so that it can be embedded as a pointer that ScanStruct allocates
+type NoteFields struct {
+	Note sql.NullString `db:"note"`
+}
+
+// TreeNode embeds a pointer to its own type, its fields must be searched only once
+type TreeNode struct {
+	ID int64
+	*TreeNode
+}
+
+type testUser struct {
+	ID   int64
+	Name string         `db:"full_name"`
+	Note sql.NullString `db:"note"`
+	Skip string         `db:"-"`
+	testAudit
+}
+
+func TestScanStruct(t *testing.T) {
+	db := mockDB(t, &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("id")}, {Name: aws.String("full_name")}, {Name: aws.String("note")}, {Name: aws.String("created_by")}},
+			Records: [][]*rdsds.Field{
+				{{LongValue: aws.Int64(1)}, {StringValue: aws.String("foo")}, {IsNull: aws.Bool(true)}, {StringValue: aws.String("admin")}},
+			},
+		}, nil
+	}})
+
+	rows, err := db.Query("SELECT id, full_name, note, created_by FROM users")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row, got: %v", rows.Err())
+	}
+
+	var u testUser
+	if err = ScanStruct(rows, &u); err != nil {
+		t.Fatalf("failed to scan struct: %v", err)
+	}
+
+	if u.ID != 1 || u.Name != "foo" || u.Note.Valid || u.CreatedBy != "admin" || u.testAudit.ID != "" {
+		t.Fatalf("unexpected scanned struct, got: %+v", u)
+	}
+
+	var withPtr struct {
+		ID        int64
+		Name      string `db:"full_name"`
+		CreatedBy string `db:"created_by"`
+		*NoteFields
+	}
+
+	if err = ScanStruct(rows, &withPtr); err != nil || withPtr.NoteFields == nil || withPtr.Note.Valid || withPtr.ID != 1 {
+		t.Fatalf("expected the embedded pointer to be allocated and scanned into, got: %+v (%v)", withPtr, err)
+	}
+
+	var unmapped struct {
+		ID int64
+		*NoteFields
+	}
+
+	if err = ScanStruct(rows, &unmapped); err == nil || unmapped.NoteFields != nil {
+		t.Fatalf("expected a failed scan to leave the embedded pointer nil, got: %+v (%v)", unmapped, err)
+	}
+
+	if err = ScanStruct(rows, u); err == nil {
+		t.Fatalf("expected error for non-pointer destination")
+	}
+
+	var other struct{ Foo string }
+	if err = ScanStruct(rows, &other); err == nil {
+		t.Fatalf("expected error for unmapped columns")
+	}
+}
+
+func TestQueryMaps(t *testing.T) {
+	db := mockDB(t, &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return &rdsds.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsds.ColumnMetadata{{Name: aws.String("id")}, {Name: aws.String("name")}},
+			Records: [][]*rdsds.Field{
+				{{LongValue: aws.Int64(1)}, {StringValue: aws.String("foo")}},
+				{{LongValue: aws.Int64(2)}, {IsNull: aws.Bool(true)}},
+			},
+		}, nil
+	}})
+
+	ms, err := QueryMaps(context.Background(), db, "SELECT id, name FROM foo")
+	if err != nil {
+		t.Fatalf("failed to query maps: %v", err)
+	}
+
+	if !reflect.DeepEqual(ms, []map[string]interface{}{
+		{"id": int64(1), "name": "foo"},
+		{"id": int64(2), "name": nil},
+	}) {
+		t.Fatalf("unexpected maps, got: %v", ms)
+	}
+}
+
+func TestNamedArgsFromStruct(t *testing.T) {
+	type base struct {
+		Tenant string `db:"tenant"`
+	}
+
+	age := int64(42)
+	v := struct {
+		*base
+		testUser
+		Age     *int64 `db:"age"`
+		Deleted *int64 `db:"deleted"`
+		Email   string `db:"email,omitempty"`
+	}{base: &base{Tenant: "acme"}, testUser: testUser{ID: 1, Name: "foo", Skip: "x"}, Age: &age}
+
+	args, err := NamedArgsFromStruct(&v)
+	if err != nil {
+		t.Fatalf("failed to get args: %v", err)
+	}
+
+	exp := []interface{}{
+		sql.Named("age", &age), sql.Named("deleted", (*int64)(nil)),
+		sql.Named("tenant", "acme"),
+		sql.Named("ID", int64(1)), sql.Named("full_name", "foo"), sql.Named("note", sql.NullString{}), sql.Named("created_by", ""),
+	}
+
+	if !reflect.DeepEqual(args, exp) {
+		t.Fatalf("expected args %v, got: %v", exp, args)
+	}
+
+	if _, err = NamedArgsFromStruct("foo"); err == nil {
+		t.Fatalf("expected error for non-struct")
+	}
+}
+
+func TestScanSelfEmbedding(t *testing.T) {
+	meta := []*rdsds.ColumnMetadata{{Name: aws.String("id"), TypeName: aws.String("BIGINT")}}
+	var n TreeNode
+	if err := DecodeRecord(meta, []*rdsds.Field{{LongValue: aws.Int64(3)}}, &n); err != nil || n.ID != 3 || n.TreeNode != nil {
+		t.Fatalf("expected the outer id to be decoded without allocating, got: %+v (%v)", n, err)
+	}
+
+	db := mockDB(t, &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return &rdsds.ExecuteStatementOutput{ColumnMetadata: meta, Records: [][]*rdsds.Field{{{LongValue: aws.Int64(4)}}}}, nil
+	}})
+
+	rows, err := db.Query("SELECT id FROM nodes")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected a row, got: %v", rows.Err())
+	}
+
+	if err = ScanStruct(rows, &n); err != nil || n.ID != 4 {
+		t.Fatalf("expected the outer id to be scanned, got: %+v (%v)", n, err)
+	}
+}
@@ -0,0 +1,77 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Execer is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ScriptResult is the result of a single statement of a script executed with ExecScript
+type ScriptResult struct {
+	Statement    string // the statement as split from the script
+	RowsAffected int64  // as reported by the Data API, zero for statements that don't write
+
+	// LastInsertID is the id generated by the statement, as with sql.Result's LastInsertId.
+	// HasLastInsertID reports whether there was one, e.g. not for postgres or DDL.
+	LastInsertID    int64
+	HasLastInsertID bool
+}
+
+// ExecScript splits the script into its statements and executes them one after the other,
+// since the Data API executes only one statement per call. It returns the result of each
+// statement, e.g. to log the progress of a migration. It stops at the first statement that
+// fails, returning the results of the statements before it and an error with the number and
+// text of the failed statement. To execute the script atomically, provide a *sql.Tx as db.
+// The script is split as with SplitStatements for the engine of the cluster, which is
+// detected if it isn't configured. The engine can't be told from a *sql.Tx, so then the
+// syntax of both engines is recognized.
+func ExecScript(ctx context.Context, db Execer, script string) (rs []ScriptResult, err error) {
+	engine, err := engineOf(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := SplitStatements(engine, script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split script: %w", err)
+	}
+
+	for i, stmt := range stmts {
+		res, err := db.ExecContext(ctx, stmt)
+		if err != nil {
+			return rs, fmt.Errorf("failed to execute statement %d of script '%s': %w", i+1, abbreviate(stmt, maxStatementInError), err)
+		}
+
+		r := ScriptResult{Statement: stmt}
+		if r.RowsAffected, err = res.RowsAffected(); err != nil {
+			return rs, fmt.Errorf("failed to get rows affected by statement %d of script: %w", i+1, err)
+		}
+
+		if id, err := res.LastInsertId(); err == nil {
+			r.LastInsertID, r.HasLastInsertID = id, true
+		}
+
+		rs = append(rs, r)
+	}
+
+	return rs, nil
+}
+
+// maxStatementInError is the nr of runes of a failed statement that are included in the error
+const maxStatementInError = 100
+
+// abbreviate returns s with its whitespace collapsed, shortened to n runes if it is longer
+func abbreviate(s string, n int) string {
+	rs := []rune(strings.Join(strings.Fields(s), " "))
+	if len(rs) <= n {
+		return string(rs)
+	}
+
+	return string(rs[:n]) + "..."
+}
@@ -0,0 +1,102 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestExecScript(t *testing.T) {
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if aws.StringValue(in.Sql) == "FAIL" {
+			return nil, awserr.New(rdsds.ErrCodeBadRequestException, "syntax error", nil)
+		}
+
+		if aws.StringValue(in.Sql) == "INSERT INTO foo VALUES (1)" {
+			return &rdsds.ExecuteStatementOutput{NumberOfRecordsUpdated: aws.Int64(1), GeneratedFields: []*rdsds.Field{{LongValue: aws.Int64(7)}}}, nil
+		}
+
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}}
+
+	cfg := testCfg
+	cfg.Engine = EngineMySQL
+
+	db := sql.OpenDB(newConnector(cfg, m))
+	rs, err := ExecScript(context.Background(), db, "CREATE TABLE foo (id INT);\nINSERT INTO foo VALUES (1);")
+	if err != nil {
+		t.Fatalf("failed to execute script: %v", err)
+	}
+
+	if len(m.executes) != 2 || aws.StringValue(m.executes[1].Sql) != "INSERT INTO foo VALUES (1)" {
+		t.Fatalf("expected two statements to be executed, got: %d", len(m.executes))
+	}
+
+	exp := []ScriptResult{
+		{Statement: "CREATE TABLE foo (id INT)"},
+		{Statement: "INSERT INTO foo VALUES (1)", RowsAffected: 1, LastInsertID: 7, HasLastInsertID: true},
+	}
+
+	if !reflect.DeepEqual(rs, exp) {
+		t.Fatalf("expected results %+v, got: %+v", exp, rs)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+
+	rs, err = ExecScript(context.Background(), tx, "DELETE FROM foo; FAIL; DROP TABLE foo")
+	if err == nil || !strings.Contains(err.Error(), "statement 2 of script 'FAIL'") {
+		t.Fatalf("expected script to fail at the second statement, got: %v", err)
+	}
+
+	if len(rs) != 1 || rs[0].Statement != "DELETE FROM foo" {
+		t.Fatalf("expected the result of the statement before the failure, got: %+v", rs)
+	}
+
+	if len(m.executes) != 4 || aws.StringValue(m.executes[2].TransactionId) != "tx1" {
+		t.Fatalf("expected script to stop in the transaction at the failed statement, got: %d", len(m.executes))
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("failed to rollback: %v", err)
+	}
+}
+
+func TestExecScriptEngine(t *testing.T) {
+	m := versionAPI("PostgreSQL 10.14 on x86_64-pc-linux-gnu")
+	rs, err := ExecScript(context.Background(), mockDB(t, m), "SELECT 1#2; SELECT 'a\\'; SELECT 3")
+	if err != nil {
+		t.Fatalf("failed to execute script: %v", err)
+	}
+
+	var act []string
+	for _, r := range rs {
+		act = append(act, r.Statement)
+	}
+
+	if exp := []string{"SELECT 1#2", "SELECT 'a\\'", "SELECT 3"}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("expected the script to be split for postgres into %q, got: %q", exp, act)
+	}
+
+	if len(m.executes) != 4 || aws.StringValue(m.executes[0].Sql) != "SELECT version()" {
+		t.Fatalf("expected the engine to be detected before the script, got: %d executions", len(m.executes))
+	}
+}
+
+func TestAbbreviate(t *testing.T) {
+	if act := abbreviate("SELECT\n  1", 10); act != "SELECT 1" {
+		t.Fatalf("expected whitespace to be collapsed, got: %q", act)
+	}
+
+	if act := abbreviate("SELECT * FROM foo", 8); act != "SELECT *..." {
+		t.Fatalf("expected statement to be shortened, got: %q", act)
+	}
+}
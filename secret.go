@@ -0,0 +1,46 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// secretsAPI describes the part of the Secrets Manager API that is used to resolve secret
+// names into the full ARN that the Data API requires.
+type secretsAPI interface {
+	DescribeSecretWithContext(aws.Context, *secretsmanager.DescribeSecretInput, ...request.Option) (*secretsmanager.DescribeSecretOutput, error)
+}
+
+// resolveSecretARN returns the ARN of the configured secret. If the secret was configured
+// by name instead of ARN it is looked up once and the resulting ARN is remembered for all
+// later connections.
+func (c *Connector) resolveSecretARN(ctx context.Context) (arn string, err error) {
+	if strings.HasPrefix(c.cfg.SecretARN, "arn:") {
+		return c.cfg.SecretARN, nil
+	}
+
+	c.secretMu.Lock()
+	defer c.secretMu.Unlock()
+	if c.secretARN != "" {
+		return c.secretARN, nil
+	}
+
+	if c.secrets == nil {
+		return "", fmt.Errorf("configuration value 'SecretARN' must be a full ARN (arn:aws:secretsmanager:...), got: '%s'", c.cfg.SecretARN)
+	}
+
+	out, err := c.secrets.DescribeSecretWithContext(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(c.cfg.SecretARN),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret '%s' to an ARN, consider configuring the full ARN as 'SecretARN': %w", c.cfg.SecretARN, err)
+	}
+
+	c.secretARN = aws.StringValue(out.ARN)
+	return c.secretARN, nil
+}
@@ -0,0 +1,44 @@
+package rdsdataapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+type mockSecrets struct{ calls int }
+
+func (m *mockSecrets) DescribeSecretWithContext(ctx aws.Context, in *secretsmanager.DescribeSecretInput, _ ...request.Option) (*secretsmanager.DescribeSecretOutput, error) {
+	m.calls++
+	return &secretsmanager.DescribeSecretOutput{ARN: aws.String("arn:aws:secretsmanager:eu-west-1:123:secret:" + aws.StringValue(in.SecretId))}, nil
+}
+
+func TestResolveSecretName(t *testing.T) {
+	cfg := testCfg
+	cfg.SecretARN = "my-secret"
+
+	c := newConnector(cfg, &mockAPI{})
+	if _, err := c.Connect(context.Background()); err == nil {
+		t.Fatalf("expected error for secret name without a way to resolve it")
+	}
+
+	m := &mockSecrets{}
+	c.secrets = m
+	for i := 0; i < 2; i++ {
+		conn, err := c.Connect(context.Background())
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+
+		if act := conn.(*Conn).secretARN; act != "arn:aws:secretsmanager:eu-west-1:123:secret:my-secret" {
+			t.Fatalf("expected resolved secret arn, got: %v", act)
+		}
+	}
+
+	if m.calls != 1 {
+		t.Fatalf("expected the secret to be resolved once, got: %d", m.calls)
+	}
+}
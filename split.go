@@ -0,0 +1,221 @@
+package rdsdataapi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SplitStatements splits a script into its statements on the semicolons that are outside of
+// quotes, identifiers and comments, following the syntax of the engine:
+//
+//   - EngineMySQL: '...' and "..." strings with backslash escapes, `...` identifiers, and
+//     '#', '-- ' and '/* */' comments
+//   - EnginePostgres: '...' strings, E'...' strings with backslash escapes, "..." identifiers,
+//     $tag$...$tag$ dollar-quoted blocks, and '--' and (nested) '/* */' comments
+//
+// Quotes are escaped by doubling them for both engines. With an empty engine the syntax of
+// both is recognized, as if backslashes only escape in single quotes. Statements are trimmed
+// of whitespace and statements with only comments are omitted, so for a well-formed script
+// joining the statements with "\n;\n" results in one that splits the same. It returns an
+// error if a quote or comment is not terminated, MySQL's DELIMITER command is not supported.
+func SplitStatements(engine, script string) (stmts []string, err error) {
+	if engine != "" && engine != EngineMySQL && engine != EnginePostgres {
+		return nil, fmt.Errorf("unsupported engine '%s', expected '%s' or '%s'", engine, EngineMySQL, EnginePostgres)
+	}
+
+	sp := splitter{rs: []rune(script), engine: engine}
+	start, content := 0, false
+	add := func(end int) {
+		if content {
+			stmts = append(stmts, strings.TrimSpace(string(sp.rs[start:end])))
+		}
+
+		start, content = end+1, false
+	}
+
+	for i := 0; i < len(sp.rs); i++ {
+		end, comment, err := sp.token(i)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case comment:
+		case end == i && sp.rs[i] == ';':
+			add(i)
+		case end > i || !unicode.IsSpace(sp.rs[i]):
+			content = true
+		}
+
+		i = end
+	}
+
+	add(len(sp.rs))
+	return stmts, nil
+}
+
+// splitter scans the runes of a script according to the syntax of an engine, an empty
+// engine recognizes the syntax of both.
+type splitter struct {
+	rs     []rune
+	engine string
+}
+
+func (sp splitter) mysql() bool    { return sp.engine != EnginePostgres }
+func (sp splitter) postgres() bool { return sp.engine != EngineMySQL }
+
+// at returns the rune at index i, or zero if i is out of range
+func (sp splitter) at(i int) rune {
+	if i < 0 || i >= len(sp.rs) {
+		return 0
+	}
+
+	return sp.rs[i]
+}
+
+// token returns the index of the last rune of the comment, quote or dollar-quoted block
+// that starts at index i, and whether it is a comment. Any other rune is a token of its own.
+func (sp splitter) token(i int) (end int, comment bool, err error) {
+	switch r := sp.rs[i]; {
+	case r == '-' && sp.at(i+1) == '-' && (sp.engine != EngineMySQL || isCommentSpace(sp.at(i+2))):
+		return sp.lineComment(i), true, nil
+	case r == '#' && sp.mysql():
+		return sp.lineComment(i), true, nil
+	case r == '/' && sp.at(i+1) == '*':
+		end, err = sp.blockComment(i)
+		return end, true, err
+	case r == '\'':
+		end, err = sp.quote(i, sp.mysql())
+		return end, false, err
+	case r == '"':
+		end, err = sp.quote(i, sp.engine == EngineMySQL)
+		return end, false, err
+	case r == '`' && sp.mysql():
+		end, err = sp.quote(i, false)
+		return end, false, err
+	case (r == 'E' || r == 'e') && sp.at(i+1) == '\'' && sp.postgres() && !isIdentRune(sp.at(i-1)):
+		end, err = sp.quote(i+1, true)
+		return end, false, err
+	case r == '$' && sp.postgres() && !isIdentRune(sp.at(i-1)):
+		if tag, ok := dollarTag(sp.rs[i:]); ok {
+			end, err = sp.dollarQuote(i, tag)
+			return end, false, err
+		}
+	}
+
+	return i, false, nil
+}
+
+// lineComment returns the index of the last rune of the comment starting at i, the newline
+// that ends it is not part of it.
+func (sp splitter) lineComment(i int) (end int) {
+	for end = i; end+1 < len(sp.rs) && sp.rs[end+1] != '\n'; end++ {
+	}
+
+	return end
+}
+
+// blockComment returns the index of the '/' that closes the comment starting at i. Postgres
+// allows block comments to be nested.
+func (sp splitter) blockComment(i int) (end int, err error) {
+	depth := 1
+	for end = i + 2; end < len(sp.rs); end++ {
+		switch {
+		case sp.rs[end] == '*' && sp.at(end+1) == '/':
+			if end, depth = end+1, depth-1; depth == 0 {
+				return end, nil
+			}
+		case sp.rs[end] == '/' && sp.at(end+1) == '*' && sp.engine == EnginePostgres:
+			end, depth = end+1, depth+1
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated comment starting at line %d", sp.line(i))
+}
+
+// quote returns the index of the rune that closes the quote starting at i. A doubled quote
+// is part of it, as is any rune after a backslash if backslash is set.
+func (sp splitter) quote(i int, backslash bool) (end int, err error) {
+	q := sp.rs[i]
+	for end = i + 1; end < len(sp.rs); end++ {
+		switch {
+		case backslash && sp.rs[end] == '\\':
+			end++
+		case sp.rs[end] == q && sp.at(end+1) == q:
+			end++
+		case sp.rs[end] == q:
+			return end, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated %c quote starting at line %d", q, sp.line(i))
+}
+
+// dollarQuote returns the index of the last rune of the tag that closes the dollar-quoted
+// block starting at i.
+func (sp splitter) dollarQuote(i int, tag []rune) (end int, err error) {
+	for end = i + len(tag); end < len(sp.rs); end++ {
+		if hasRunePrefix(sp.rs[end:], tag) {
+			return end + len(tag) - 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated dollar-quoted block %s starting at line %d", string(tag), sp.line(i))
+}
+
+// line returns the 1-based line number of the rune at index i
+func (sp splitter) line(i int) int {
+	n := 1
+	for _, r := range sp.rs[:i] {
+		if r == '\n' {
+			n++
+		}
+	}
+
+	return n
+}
+
+// isCommentSpace returns whether r may follow '--' to start a MySQL comment, which requires
+// whitespace or a control character (or the end of the script).
+func isCommentSpace(r rune) bool {
+	return r == 0 || unicode.IsSpace(r) || unicode.IsControl(r)
+}
+
+// isIdentRune returns whether r can be part of an unquoted identifier, such that a '$' or
+// 'E' that follows it doesn't start a quote.
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+// dollarTag returns the tag that opens a postgres dollar-quoted block at the start of rs,
+// e.g. '$$' or '$body$'. It reports false if rs doesn't start with such a tag, e.g. for a
+// positional parameter like '$1'.
+func dollarTag(rs []rune) ([]rune, bool) {
+	for i := 1; i < len(rs); i++ {
+		switch r := rs[i]; {
+		case r == '$':
+			return rs[:i+1], true
+		case unicode.IsLetter(r) || r == '_' || (unicode.IsDigit(r) && i > 1):
+		default:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// hasRunePrefix returns whether rs starts with prefix
+func hasRunePrefix(rs, prefix []rune) bool {
+	if len(rs) < len(prefix) {
+		return false
+	}
+
+	for i, r := range prefix {
+		if rs[i] != r {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,48 @@
+//go:build go1.18
+// +build go1.18
+
+package rdsdataapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func FuzzSplitStatements(f *testing.F) {
+	for _, s := range []string{
+		"SELECT 1; SELECT 2",
+		"INSERT INTO foo VALUES ('a;b', \"c;d\", `e;f`, 'it''s', 'esc\\'');",
+		"-- comment;\nSELECT 1 # other; comment\n; /* block; */ SELECT 2 --",
+		"CREATE FUNCTION f() RETURNS int AS $fn$ BEGIN RETURN 1; END; $fn$ LANGUAGE plpgsql; SELECT $1",
+		"SELECT E'\\';'; /* a /* b */ c */ SELECT a$b$c",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, script string) {
+		for _, engine := range []string{"", EngineMySQL, EnginePostgres} {
+			stmts, err := SplitStatements(engine, script)
+			if err != nil {
+				continue
+			}
+
+			for _, stmt := range stmts {
+				if stmt == "" || stmt != strings.TrimSpace(stmt) {
+					t.Fatalf("%s: expected trimmed, non-empty statements, got: %q", engine, stmt)
+				}
+
+				// a trailing '--' is not a MySQL comment without the newline that joining adds,
+				// statements can't end in an operator so such scripts are not well-formed
+				if engine == EngineMySQL && strings.HasSuffix(stmt, "--") {
+					return
+				}
+			}
+
+			again, err := SplitStatements(engine, strings.Join(stmts, "\n;\n"))
+			if err != nil || !reflect.DeepEqual(again, stmts) {
+				t.Fatalf("%s: expected re-joined statements %q to split the same, got: %q (%v)", engine, stmts, again, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package rdsdataapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	for i, c := range []struct {
+		script string
+		exp    []string
+	}{
+		{"SELECT 1", []string{"SELECT 1"}},
+		{"SELECT 1; SELECT 2;\n", []string{"SELECT 1", "SELECT 2"}},
+		{"INSERT INTO foo VALUES ('a;b', \"c;d\", `e;f`);", []string{"INSERT INTO foo VALUES ('a;b', \"c;d\", `e;f`)"}},
+		{"INSERT INTO foo VALUES ('it''s; here', 'esc\\'; aped')", []string{"INSERT INTO foo VALUES ('it''s; here', 'esc\\'; aped')"}},
+		{"-- first; comment\nSELECT 1; /* second; */ SELECT 2", []string{"-- first; comment\nSELECT 1", "/* second; */ SELECT 2"}},
+		{"SELECT 1; -- trailing comment;", []string{"SELECT 1"}},
+		{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql; SELECT f()",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT f()"}},
+		{"DO $body$ BEGIN PERFORM 1; END $body$; SELECT $1", []string{"DO $body$ BEGIN PERFORM 1; END $body$", "SELECT $1"}},
+		{";;  ;", nil},
+	} {
+		if act, err := SplitStatements("", c.script); err != nil || !reflect.DeepEqual(act, c.exp) {
+			t.Fatalf("%d: expected %q, got: %q (%v)", i, c.exp, act, err)
+		}
+	}
+}
+
+func TestSplitStatementsEngines(t *testing.T) {
+	for i, c := range []struct {
+		engine, script string
+		exp            []string
+	}{
+		{EngineMySQL, "SELECT 'a\\';b'; SELECT \"c\\\";d\"", []string{"SELECT 'a\\';b'", "SELECT \"c\\\";d\""}},
+		{EngineMySQL, "SELECT `a``;b`; # c;\nSELECT 2", []string{"SELECT `a``;b`", "# c;\nSELECT 2"}},
+		{EngineMySQL, "SELECT 1--1; SELECT 2 -- x;\n", []string{"SELECT 1--1", "SELECT 2 -- x;"}},
+		{EngineMySQL, "SELECT $$a;b$$", []string{"SELECT $$a", "b$$"}},
+		{EnginePostgres, "SELECT 'a\\'; SELECT E'b\\';c'", []string{"SELECT 'a\\'", "SELECT E'b\\';c'"}},
+		{EnginePostgres, "SELECT \"a\"\";b\"; SELECT 1#2", []string{"SELECT \"a\"\";b\"", "SELECT 1#2"}},
+		{EnginePostgres, "/* a /* b; */ c; */ SELECT 1; SELECT 2", []string{"/* a /* b; */ c; */ SELECT 1", "SELECT 2"}},
+		{EnginePostgres, "SELECT $fn$ a $$; $fn$; SELECT a$b$c; SELECT $1", []string{"SELECT $fn$ a $$; $fn$", "SELECT a$b$c", "SELECT $1"}},
+		{EnginePostgres, "SELECT `a;b`", []string{"SELECT `a", "b`"}},
+	} {
+		if act, err := SplitStatements(c.engine, c.script); err != nil || !reflect.DeepEqual(act, c.exp) {
+			t.Fatalf("%d: expected %q, got: %q (%v)", i, c.exp, act, err)
+		}
+	}
+}
+
+func TestSplitStatementsErrors(t *testing.T) {
+	for i, c := range []struct {
+		engine, script, exp string
+	}{
+		{"", "SELECT 1;\nSELECT 'a", "unterminated ' quote starting at line 2"},
+		{EngineMySQL, "SELECT `a", "unterminated ` quote"},
+		{EnginePostgres, "SELECT 1 /* a /* b */", "unterminated comment starting at line 1"},
+		{EnginePostgres, "DO $body$ BEGIN", "unterminated dollar-quoted block $body$"},
+		{"oracle", "SELECT 1", "unsupported engine"},
+	} {
+		if _, err := SplitStatements(c.engine, c.script); err == nil || !strings.Contains(err.Error(), c.exp) {
+			t.Fatalf("%d: expected error containing '%s', got: %v", i, c.exp, err)
+		}
+	}
+}
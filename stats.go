@@ -0,0 +1,73 @@
+package rdsdataapi
+
+import "sync/atomic"
+
+// Stats is a snapshot of the activity of a connector, it complements sql.DBStats with
+// counts that are specific to the driver.
+type Stats struct {
+	Connections int64 // connections opened
+	Begun       int64 // transactions begun
+	Committed   int64 // transactions committed
+	RolledBack  int64 // transactions rolled back
+	Executed    int64 // statements executed successfully
+	Batches     int64 // batches of prepared statements executed successfully
+	Retries     int64 // failed calls to the Data API that were retried
+}
+
+// stat identifies one of the counters of a connector
+type stat int
+
+const (
+	statConnections stat = iota
+	statBegun
+	statCommitted
+	statRolledBack
+	statExecuted
+	statBatches
+	statRetries
+	numStats
+)
+
+// opStats maps the operations that were reported as successful to their counter
+var opStats = map[string]stat{
+	OpBegin:    statBegun,
+	OpCommit:   statCommitted,
+	OpRollback: statRolledBack,
+	OpExecute:  statExecuted,
+	OpBatch:    statBatches,
+}
+
+// counters are updated atomically by the connections of a connector. A nil counters
+// ignores all updates.
+type counters [numStats]int64
+
+// inc increments the counter for s
+func (cs *counters) inc(s stat) {
+	if cs != nil {
+		atomic.AddInt64(&cs[s], 1)
+	}
+}
+
+// Stats returns a snapshot of the activity of all connections of the connector. The
+// counters are shared with the connectors created by WithDatabase.
+func (c *Connector) Stats() Stats {
+	get := func(s stat) int64 { return atomic.LoadInt64(&c.counters[s]) }
+	return Stats{
+		Connections: get(statConnections),
+		Begun:       get(statBegun),
+		Committed:   get(statCommitted),
+		RolledBack:  get(statRolledBack),
+		Executed:    get(statExecuted),
+		Batches:     get(statBatches),
+		Retries:     get(statRetries),
+	}
+}
+
+// counters returns the counters of the connector that created the connection, if any
+func (c *Conn) counters() *counters {
+	if c.connector == nil {
+		return nil
+	}
+
+	return c.connector.counters
+}
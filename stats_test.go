@@ -0,0 +1,73 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestConnectorStats(t *testing.T) {
+	throttled := false
+	m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		if !throttled {
+			throttled = true
+			return nil, awserr.New("ThrottlingException", "slow down", nil)
+		}
+
+		return &rdsds.ExecuteStatementOutput{}, nil
+	}}
+
+	conn := newConnector(testCfg, m)
+	conn.retryer = instantRetryer(1)
+	conn.retryer.counters = conn.counters
+
+	db := sql.OpenDB(conn)
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "DELETE FROM foo"); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	for _, commit := range []bool{true, false} {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+
+		if commit {
+			err = tx.Commit()
+		} else {
+			err = tx.Rollback()
+		}
+
+		if err != nil {
+			t.Fatalf("failed to end transaction: %v", err)
+		}
+	}
+
+	s, err := db.PrepareContext(ctx, "INSERT INTO foo VALUES (:id)")
+	if err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+
+	if _, err = s.ExecContext(ctx, sql.Named("id", 1)); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+
+	if err = s.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	exp := Stats{Connections: 1, Begun: 2, Committed: 1, RolledBack: 1, Executed: 1, Batches: 1, Retries: 1}
+	if act := conn.Stats(); act != exp {
+		t.Fatalf("expected stats %+v, got: %+v", exp, act)
+	}
+
+	if act := conn.WithDatabase("other").Stats(); act != exp {
+		t.Fatalf("expected copy to share the stats, got: %+v", act)
+	}
+}
@@ -0,0 +1,112 @@
+package rdsdataapi
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// analysis holds what the driver derives from the sql of a statement to execute it, it only
+// depends on the sql and the engine so it can be cached, see StatementCacheSize.
+type analysis struct {
+	kind      StatementKind
+	unbounded bool   // see isUnboundedWrite
+	metadata  bool   // see includeMetadata
+	use       string // the database a 'USE <database>' statement switches to
+	isUse     bool
+}
+
+// analyzeStatement analyzes the sql of a statement for the syntax of the engine, or of both
+// engines if it is empty
+func analyzeStatement(engine, query string) (a analysis) {
+	a.kind = classifyStatement(engine, query)
+	a.unbounded = isUnboundedWrite(engine, query, a.kind)
+	a.metadata = includeMetadata(a.kind, query)
+	if m := useStmt.FindStringSubmatch(query); m != nil {
+		a.use, a.isUse = strings.Trim(m[1], "`\""), true
+	}
+
+	return
+}
+
+// analyze returns the analysis of the statement for the known engine, from the connector's
+// cache if it has one
+func (c *Conn) analyze(query string) analysis {
+	engine := c.knownEngine()
+	if c.connector == nil || c.connector.stmts == nil {
+		return analyzeStatement(engine, query)
+	}
+
+	sc, key := c.connector.stmts, stmtKey{engine, query}
+	if a, ok := sc.get(key); ok {
+		return a
+	}
+
+	a := analyzeStatement(engine, query)
+	sc.put(key, a)
+	return a
+}
+
+// stmtKey identifies a cached analysis, the engine is part of it as it may only become known
+// after statements were analyzed for both engines
+type stmtKey struct{ engine, query string }
+
+// stmtCache is a least recently used cache of statement analyses by their sql. It is
+// shared by all connections of a connector and safe for concurrent use.
+type stmtCache struct {
+	size int
+
+	mu    sync.Mutex
+	order *list.List // of *stmtCacheEntry, the most recently used first
+	items map[stmtKey]*list.Element
+}
+
+type stmtCacheEntry struct {
+	key stmtKey
+	a   analysis
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{size: size, order: list.New(), items: make(map[stmtKey]*list.Element, size)}
+}
+
+// get returns the analysis of the query if it is cached, marking it as recently used
+func (sc *stmtCache) get(key stmtKey) (analysis, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	el, ok := sc.items[key]
+	if !ok {
+		return analysis{}, false
+	}
+
+	sc.order.MoveToFront(el)
+	return el.Value.(*stmtCacheEntry).a, true
+}
+
+// put caches the analysis of the query, evicting the least recently used one if the cache
+// is full.
+func (sc *stmtCache) put(key stmtKey, a analysis) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if el, ok := sc.items[key]; ok {
+		el.Value.(*stmtCacheEntry).a = a
+		sc.order.MoveToFront(el)
+		return
+	}
+
+	sc.items[key] = sc.order.PushFront(&stmtCacheEntry{key: key, a: a})
+	if sc.order.Len() > sc.size {
+		oldest := sc.order.Back()
+		sc.order.Remove(oldest)
+		delete(sc.items, oldest.Value.(*stmtCacheEntry).key)
+	}
+}
+
+// len returns the nr of cached analyses
+func (sc *stmtCache) len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.order.Len()
+}
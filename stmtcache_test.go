@@ -0,0 +1,92 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestStmtCacheEviction(t *testing.T) {
+	sc := newStmtCache(2)
+	sc.put(stmtKey{"", "SELECT 1"}, analysis{kind: StatementSelect})
+	sc.put(stmtKey{"", "DELETE FROM foo"}, analysis{kind: StatementDelete, unbounded: true})
+	if _, ok := sc.get(stmtKey{"", "SELECT 1"}); !ok {
+		t.Fatalf("expected the analysis to be cached")
+	}
+
+	sc.put(stmtKey{"", "USE bar"}, analysis{use: "bar", isUse: true})
+	if _, ok := sc.get(stmtKey{"", "DELETE FROM foo"}); ok || sc.len() != 2 {
+		t.Fatalf("expected the least recently used analysis to be evicted, got %d cached", sc.len())
+	}
+
+	if a, ok := sc.get(stmtKey{"", "SELECT 1"}); !ok || a.kind != StatementSelect {
+		t.Fatalf("expected the recently used analysis to be kept, got: %v", a)
+	}
+}
+
+func TestStmtCacheConcurrency(t *testing.T) {
+	sc := newStmtCache(8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				q := fmt.Sprintf("SELECT %d", (i+j)%16)
+				if _, ok := sc.get(stmtKey{"", q}); !ok {
+					sc.put(stmtKey{"", q}, analyzeStatement("", q))
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	if sc.len() != 8 {
+		t.Fatalf("expected the cache to be full, got: %d", sc.len())
+	}
+}
+
+func TestConnStatementCache(t *testing.T) {
+	cfg := testCfg
+	cfg.StatementCacheSize, cfg.GuardUnboundedWrites = 10, GuardReject
+	c := newConnector(cfg, &mockAPI{})
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = conn.(*Conn).ExecContext(context.Background(), "DELETE FROM foo", nil); err == nil {
+			t.Fatalf("expected the cached analysis to be guarded as well")
+		}
+
+		if _, err = conn.(*Conn).ExecContext(context.Background(), "USE bar", nil); err != nil || conn.(*Conn).databaseName != "bar" {
+			t.Fatalf("expected the cached analysis to switch the database, got: %v", err)
+		}
+	}
+
+	if a, ok := c.stmts.get(stmtKey{"", "DELETE FROM foo"}); !ok || !a.unbounded || c.stmts.len() != 2 {
+		t.Fatalf("expected the analyses to be cached, got: %d", c.stmts.len())
+	}
+
+	if c.WithDatabase("other").stmts != c.stmts {
+		t.Fatalf("expected the cache to be shared with connectors for other databases")
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	query := "WITH recent AS (SELECT id FROM orders WHERE created > :since) UPDATE orders SET archived = true WHERE id IN (SELECT id FROM recent)"
+	conn := &Conn{connector: newConnector(Config{StatementCacheSize: 100}, &mockAPI{})}
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			analyzeStatement("", query)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			conn.analyze(query)
+		}
+	})
+}
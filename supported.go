@@ -0,0 +1,62 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// Supported checks whether the cluster of the config can be used through the Data API by
+// executing the ValidationQuery outside of a transaction. It returns false and an error
+// wrapping ErrDataAPIDisabled when the Data API is not enabled for the cluster. Other
+// failures, e.g. a missing permission, are returned as an error along with false as well.
+func Supported(ctx context.Context, cfg Config) (bool, error) {
+	c, err := NewConnector(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	return c.supported(ctx)
+}
+
+// supported checks the Data API with the connector's client, see Supported
+func (c *Connector) supported(ctx context.Context) (bool, error) {
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Close()
+	cn := conn.(*Conn)
+
+	// the API is called directly, so configured implicit transactions don't apply
+	_, err = cn.rdsDataService.ExecuteStatementWithContext(ctx, &rdsds.ExecuteStatementInput{
+		Database:    aws.String(cn.databaseName),
+		ResourceArn: aws.String(cn.resourceARN),
+		SecretArn:   aws.String(cn.secretARN),
+		Sql:         aws.String(c.cfg.validationQuery()),
+	})
+	if err == nil {
+		return true, nil
+	}
+
+	if err = cn.checkErr(err); errors.Is(err, ErrDataAPIDisabled) {
+		return false, err
+	}
+
+	return false, fmt.Errorf("failed to check the Data API: %w", err)
+}
+
+// Check is a health check for a database handle of this driver. It executes the configured
+// ValidationQuery on one of its connections and returns an error if it fails.
+func Check(ctx context.Context, db *sql.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestSupported(t *testing.T) {
+	for i, c := range []struct {
+		err         error
+		expOK       bool
+		expDisabled bool
+	}{
+		{nil, true, false},
+		{awserr.New(rdsds.ErrCodeBadRequestException, "HttpEndpoint is not enabled for cluster foo. Please refer to https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/data-api.html#data-api.troubleshooting", nil), false, true},
+		{awserr.New(errCodeAccessDenied, "User is not authorized to perform: rds-data:ExecuteStatement", nil), false, false},
+	} {
+		m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+			return &rdsds.ExecuteStatementOutput{}, c.err
+		}}
+
+		cfg := testCfg
+		cfg.ImplicitTx = true
+		ok, err := newConnector(cfg, m).supported(context.Background())
+		if ok != c.expOK || errors.Is(err, ErrDataAPIDisabled) != c.expDisabled || (err == nil) != c.expOK {
+			t.Fatalf("%d: expected supported to be %v (disabled: %v), got: %v (%v)", i, c.expOK, c.expDisabled, ok, err)
+		}
+
+		var aerr awserr.Error
+		if c.expDisabled && (!strings.Contains(err.Error(), "enable-http-endpoint") || !errors.As(err, &aerr)) {
+			t.Fatalf("%d: expected a hint on enabling the Data API that wraps the aws error, got: %v", i, err)
+		}
+
+		if len(m.begins) != 0 || len(m.executes) != 1 {
+			t.Fatalf("%d: expected a single statement outside of a transaction, got: %d begins", i, len(m.begins))
+		}
+	}
+}
+
+func TestValidationQuery(t *testing.T) {
+	m := &mockAPI{}
+	cfg := testCfg
+	cfg.ValidationQuery = "SELECT 1 FROM health"
+	db := sql.OpenDB(newConnector(cfg, m))
+
+	if err := Check(context.Background(), db); err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+
+	if len(m.executes) != 1 || *m.executes[0].Sql != cfg.ValidationQuery {
+		t.Fatalf("expected the validation query to be executed, got: %d statements", len(m.executes))
+	}
+
+	m.ExecuteStatement = func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+		return nil, awserr.New(rdsds.ErrCodeServiceUnavailableError, "unavailable", nil)
+	}
+
+	if err := Check(context.Background(), db); err == nil || !strings.Contains(err.Error(), "health check failed") {
+		t.Fatalf("expected check to fail, got: %v", err)
+	}
+
+	conn := mockConn(t, &mockAPI{})
+	conn.Close()
+	if conn.IsValid() || conn.Ping(context.Background()) == nil {
+		t.Fatalf("expected closed connection to be invalid")
+	}
+
+	if _, err := ParseDSN("ValidationQuery=DELETE FROM foo"); err == nil {
+		t.Fatalf("expected error for a validation query that writes")
+	}
+
+	if _, err := NewConnector(Config{Database: "db", ResourceARN: "arn:res", SecretARN: "arn:sec", ValidationQuery: "DROP TABLE foo"}); err == nil {
+		t.Fatalf("expected error for a validation query that writes")
+	}
+}
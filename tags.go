@@ -0,0 +1,57 @@
+package rdsdataapi
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// userAgent formats the tags as User-Agent components, e.g: 'service/billing team/payments'.
+// The Data API doesn't support tagging requests, so this allows calls to be attributed in
+// CloudTrail, which records the User-Agent of each call.
+func userAgent(tags map[string]string) string {
+	return formatTags(tags, "/", " ")
+}
+
+// formatTags formats the tags, sorted by key, as key-sep-value pairs joined by join
+func formatTags(tags map[string]string, sep, join string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + sep + tags[k]
+	}
+
+	return strings.Join(pairs, join)
+}
+
+// parseTags parses tags formatted as 'key:value,key:value'
+func parseTags(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("configuration value 'Tags' must be formatted as 'key:value,key:value', got: '%s'", s)
+		}
+
+		tags[kv[0]] = kv[1]
+	}
+
+	return tags, nil
+}
+
+// logf logs a message of the driver with the standard logger, with the configured tags
+// appended as key=value fields.
+func (c *Conn) logf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if len(c.cfg.Tags) > 0 {
+		msg += " " + formatTags(c.cfg.Tags, "=", " ")
+	}
+
+	log.Print("rdsdataapi: " + msg)
+}
@@ -0,0 +1,49 @@
+package rdsdataapi
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestTags(t *testing.T) {
+	tags, err := parseTags("team:payments,service:billing")
+	if err != nil || len(tags) != 2 || tags["team"] != "payments" {
+		t.Fatalf("failed to parse tags: %v (%v)", tags, err)
+	}
+
+	for _, s := range []string{"team", ":payments", "team:payments,"} {
+		if _, err = parseTags(s); err == nil {
+			t.Fatalf("expected error for tags '%s'", s)
+		}
+	}
+
+	cfg := testCfg
+	cfg.Tags = tags
+	cn, err := NewConnector(cfg)
+	if err != nil {
+		t.Fatalf("failed to create connector: %v", err)
+	}
+
+	api := cn.rdsDataService.(*rdsds.RDSDataService)
+	r := &request.Request{HTTPRequest: &http.Request{Header: http.Header{}}}
+	api.Handlers.Build.Run(r)
+	if ua := r.HTTPRequest.Header.Get("User-Agent"); !strings.HasSuffix(ua, "service/billing team/payments") {
+		t.Fatalf("expected tags in the user agent, got: '%s'", ua)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	(&Conn{cfg: cfg}).logf("foo %d", 1)
+	if !strings.Contains(buf.String(), "rdsdataapi: foo 1 service=billing team=payments") {
+		t.Fatalf("expected tags in the log message, got: '%s'", buf.String())
+	}
+}
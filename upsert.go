@@ -0,0 +1,85 @@
+package rdsdataapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// UpsertOutcome describes what an upsert did to the row
+type UpsertOutcome int
+
+const (
+	// UpsertUnchanged means the row existed and was left as is
+	UpsertUnchanged UpsertOutcome = iota
+	// UpsertInserted means a new row was inserted
+	UpsertInserted
+	// UpsertUpdated means the existing row was updated
+	UpsertUpdated
+	// UpsertUnknown means a row was inserted or updated, but the driver can't tell which
+	UpsertUnknown
+)
+
+func (o UpsertOutcome) String() string {
+	switch o {
+	case UpsertInserted:
+		return "inserted"
+	case UpsertUpdated:
+		return "updated"
+	case UpsertUnknown:
+		return "unknown"
+	default:
+		return "unchanged"
+	}
+}
+
+// UpsertResult is the normalized result of a single row upsert
+type UpsertResult struct {
+	Outcome UpsertOutcome
+	ID      int64 // the generated id, only set if HasID is true
+	HasID   bool
+}
+
+// Upsert executes a single row upsert, such as MySQL's INSERT ... ON DUPLICATE KEY UPDATE or
+// postgres' INSERT ... ON CONFLICT, and normalizes the reported nr of affected rows: 0 means
+// the row was unchanged, 1 that it was inserted and 2 that it was updated. The generated id
+// is included when the Data API reports a non-zero one. Postgres reports 1 affected row for
+// both an insert and an update, so for 1 affected row the outcome is UpsertUnknown unless
+// the cluster is known to run MySQL. That is also the case if the engine can't be told from
+// db, e.g. for a *sql.Tx. Add a RETURNING clause, e.g. `RETURNING (xmax = 0) AS inserted`,
+// and query it instead if the two need to be told apart on postgres.
+func Upsert(ctx context.Context, db Execer, query string, args ...interface{}) (res UpsertResult, err error) {
+	engine, err := engineOf(ctx, db)
+	if err != nil {
+		return res, err
+	}
+
+	r, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return res, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	switch n {
+	case 0:
+		res.Outcome = UpsertUnchanged
+	case 1:
+		res.Outcome = UpsertInserted
+		if engine != EngineMySQL {
+			res.Outcome = UpsertUnknown
+		}
+	case 2:
+		res.Outcome = UpsertUpdated
+	default:
+		return res, fmt.Errorf("upsert affected %d rows, only single row upserts are supported", n)
+	}
+
+	if id, err := r.LastInsertId(); err == nil && id != 0 {
+		res.ID, res.HasID = id, true
+	}
+
+	return res, nil
+}
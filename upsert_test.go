@@ -0,0 +1,57 @@
+package rdsdataapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	rdsds "github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestUpsert(t *testing.T) {
+	for i, c := range []struct {
+		engine    string
+		tx        bool
+		updated   int64
+		generated []*rdsds.Field
+		exp       UpsertResult
+		expErr    bool
+	}{
+		{EngineMySQL, false, 1, []*rdsds.Field{{LongValue: aws.Int64(7)}}, UpsertResult{UpsertInserted, 7, true}, false},
+		{EngineMySQL, false, 2, []*rdsds.Field{{LongValue: aws.Int64(0)}}, UpsertResult{UpsertUpdated, 0, false}, false},
+		{EngineMySQL, false, 0, nil, UpsertResult{UpsertUnchanged, 0, false}, false},
+		{EngineMySQL, false, 1, nil, UpsertResult{UpsertInserted, 0, false}, false},
+		{EngineMySQL, false, 3, nil, UpsertResult{}, true},
+		{EngineMySQL, true, 1, nil, UpsertResult{UpsertUnknown, 0, false}, false},
+		{EnginePostgres, false, 1, nil, UpsertResult{UpsertUnknown, 0, false}, false},
+		{EnginePostgres, false, 0, nil, UpsertResult{UpsertUnchanged, 0, false}, false},
+	} {
+		m := &mockAPI{ExecuteStatement: func(in *rdsds.ExecuteStatementInput) (*rdsds.ExecuteStatementOutput, error) {
+			return &rdsds.ExecuteStatementOutput{NumberOfRecordsUpdated: aws.Int64(c.updated), GeneratedFields: c.generated}, nil
+		}}
+
+		cfg := testCfg
+		cfg.Engine = c.engine
+
+		var db Execer = sql.OpenDB(newConnector(cfg, m))
+		if c.tx {
+			tx, err := db.(*sql.DB).Begin()
+			if err != nil {
+				t.Fatalf("%d: failed to begin: %v", i, err)
+			}
+
+			defer tx.Rollback()
+			db = tx
+		}
+
+		res, err := Upsert(context.Background(), db, "INSERT INTO foo (id, n) VALUES (1, 1) ON DUPLICATE KEY UPDATE n = n + 1")
+		if (err != nil) != c.expErr {
+			t.Fatalf("%d: expected error to be %v, got: %v", i, c.expErr, err)
+		}
+
+		if res != c.exp {
+			t.Fatalf("%d: expected %+v, got: %+v", i, c.exp, res)
+		}
+	}
+}